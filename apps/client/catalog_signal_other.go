@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// notifyCatalogDump SIGUSR1이 없는 플랫폼에서는 종료 시에만 카탈로그를 덤프합니다
+func notifyCatalogDump(ch chan<- os.Signal) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyCatalogDump SIGUSR1 수신 시 카탈로그 덤프를 요청하도록 등록합니다
+func notifyCatalogDump(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
@@ -0,0 +1,125 @@
+package combat
+
+import (
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// 대상 하나에 대해 매칭을 기다리는 공격의 최대 개수
+const maxPendingAttacksPerTarget = 256
+
+// Damage HP 감소와 그 원인으로 추정되는 공격을 연결한 피해 기록
+type Damage struct {
+	Timestamp    time.Time
+	AttackerID   uint32 // 귀속되지 않았으면 0
+	TargetID     uint32
+	Amount       uint32
+	Attack       packet.AttackData // 연결된 공격 (귀속되지 않았으면 빈 값)
+	Ambiguous    bool              // 시간 창 안에 서로 다른 공격자의 공격이 여러 개 있어 순서로 추정함
+	Unattributed bool              // 시간 창 안에 연결할 공격이 없음
+}
+
+type pendingAttack struct {
+	timestamp time.Time
+	attack    packet.AttackData
+}
+
+// Attributor 대상별 HP 감소를 먼저 도착한 공격 이벤트에 순서대로 연결합니다.
+// 시각은 모두 캡처 시각 기준이며, 고루틴 안전하지 않습니다.
+type Attributor struct {
+	window    time.Duration
+	pending   map[uint32][]pendingAttack
+	lastSweep time.Time
+}
+
+func NewAttributor(window time.Duration) *Attributor {
+	return &Attributor{
+		window:  window,
+		pending: make(map[uint32][]pendingAttack),
+	}
+}
+
+// AddAttack HP 감소와 매칭할 공격을 대기열에 추가합니다
+func (a *Attributor) AddAttack(timestamp time.Time, attack packet.AttackData) {
+	a.sweep(timestamp)
+
+	queue := a.expire(a.pending[attack.TargetID], timestamp)
+	if len(queue) >= maxPendingAttacksPerTarget {
+		queue = queue[1:]
+	}
+
+	a.pending[attack.TargetID] = append(queue, pendingAttack{
+		timestamp: timestamp,
+		attack:    attack,
+	})
+}
+
+// AddHP HP 감소를 가장 오래된 대기 공격에 연결합니다. HP가 줄지 않았으면 false를 반환합니다.
+func (a *Attributor) AddHP(timestamp time.Time, hp packet.HPData) (Damage, bool) {
+	a.sweep(timestamp)
+
+	if hp.Damage == 0 {
+		return Damage{}, false
+	}
+
+	damage := Damage{
+		Timestamp: timestamp,
+		TargetID:  hp.TargetID,
+		Amount:    hp.Damage,
+	}
+
+	queue := a.expire(a.pending[hp.TargetID], timestamp)
+	if len(queue) == 0 {
+		delete(a.pending, hp.TargetID)
+		damage.Unattributed = true
+		return damage, true
+	}
+
+	matched := queue[0]
+	for _, candidate := range queue[1:] {
+		if candidate.attack.UserID != matched.attack.UserID {
+			damage.Ambiguous = true
+			break
+		}
+	}
+
+	damage.AttackerID = matched.attack.UserID
+	damage.Attack = matched.attack
+
+	if len(queue) == 1 {
+		delete(a.pending, hp.TargetID)
+	} else {
+		a.pending[hp.TargetID] = queue[1:]
+	}
+
+	return damage, true
+}
+
+// expire 시간 창을 벗어난 공격을 대기열 앞에서 제거합니다
+func (a *Attributor) expire(queue []pendingAttack, now time.Time) []pendingAttack {
+	cutoff := now.Add(-a.window)
+
+	i := 0
+	for i < len(queue) && queue[i].timestamp.Before(cutoff) {
+		i++
+	}
+
+	return queue[i:]
+}
+
+// sweep 한동안 HP 이벤트가 오지 않은 대상들의 대기열을 정리합니다
+func (a *Attributor) sweep(now time.Time) {
+	if now.Sub(a.lastSweep) < a.window {
+		return
+	}
+	a.lastSweep = now
+
+	for targetID, queue := range a.pending {
+		if queue = a.expire(queue, now); len(queue) == 0 {
+			delete(a.pending, targetID)
+		} else {
+			a.pending[targetID] = queue
+		}
+	}
+}
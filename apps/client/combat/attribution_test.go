@@ -0,0 +1,169 @@
+package combat
+
+import (
+	"testing"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+var testStart = time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC)
+
+// at 테스트 시작 시각에서 ms 밀리초 뒤
+func at(ms int) time.Time {
+	return testStart.Add(time.Duration(ms) * time.Millisecond)
+}
+
+func attack(userID, targetID, key1 uint32) packet.AttackData {
+	return packet.AttackData{UserID: userID, TargetID: targetID, Key1: key1}
+}
+
+func hpDrop(targetID, amount uint32) packet.HPData {
+	return packet.HPData{TargetID: targetID, Prev: 100000, Current: 100000 - amount, Damage: amount}
+}
+
+// attributionStep 시각 순서대로 공격 또는 HP 이벤트 하나
+type attributionStep struct {
+	ms     int
+	attack *packet.AttackData
+	hp     *packet.HPData
+	want   *Damage // HP 이벤트의 기대 결과 (nil이면 결과가 없어야 함)
+}
+
+func TestAttributorSequences(t *testing.T) {
+	ptr := func(v packet.AttackData) *packet.AttackData { return &v }
+	hp := func(v packet.HPData) *packet.HPData { return &v }
+
+	tests := []struct {
+		name  string
+		steps []attributionStep
+	}{
+		{
+			name: "single attack",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 300, hp: hp(hpDrop(100, 50)), want: &Damage{AttackerID: 1, TargetID: 100, Amount: 50, Attack: attack(1, 100, 11)}},
+			},
+		},
+		{
+			name: "oldest pending attack first",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 100, attack: ptr(attack(1, 100, 12))},
+				{ms: 200, hp: hp(hpDrop(100, 10)), want: &Damage{AttackerID: 1, TargetID: 100, Amount: 10, Attack: attack(1, 100, 11)}},
+				{ms: 300, hp: hp(hpDrop(100, 20)), want: &Damage{AttackerID: 1, TargetID: 100, Amount: 20, Attack: attack(1, 100, 12)}},
+				{ms: 400, hp: hp(hpDrop(100, 30)), want: &Damage{TargetID: 100, Amount: 30, Unattributed: true}},
+			},
+		},
+		{
+			name: "ambiguous when several attackers are pending",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 50, attack: ptr(attack(2, 100, 21))},
+				{ms: 100, hp: hp(hpDrop(100, 10)), want: &Damage{AttackerID: 1, TargetID: 100, Amount: 10, Attack: attack(1, 100, 11), Ambiguous: true}},
+				// 남은 공격자가 한 명뿐이면 더 이상 모호하지 않습니다
+				{ms: 150, hp: hp(hpDrop(100, 20)), want: &Damage{AttackerID: 2, TargetID: 100, Amount: 20, Attack: attack(2, 100, 21)}},
+			},
+		},
+		{
+			name: "targets are matched separately",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 10, attack: ptr(attack(2, 200, 21))},
+				{ms: 20, hp: hp(hpDrop(200, 5)), want: &Damage{AttackerID: 2, TargetID: 200, Amount: 5, Attack: attack(2, 200, 21)}},
+				{ms: 30, hp: hp(hpDrop(100, 7)), want: &Damage{AttackerID: 1, TargetID: 100, Amount: 7, Attack: attack(1, 100, 11)}},
+			},
+		},
+		{
+			name: "attack exactly at the window edge still matches",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 2000, hp: hp(hpDrop(100, 10)), want: &Damage{AttackerID: 1, TargetID: 100, Amount: 10, Attack: attack(1, 100, 11)}},
+			},
+		},
+		{
+			name: "attack older than the window is unattributed",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 2001, hp: hp(hpDrop(100, 10)), want: &Damage{TargetID: 100, Amount: 10, Unattributed: true}},
+			},
+		},
+		{
+			name: "expired attack is skipped for a newer one",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 1900, attack: ptr(attack(2, 100, 21))},
+				{ms: 2500, hp: hp(hpDrop(100, 10)), want: &Damage{AttackerID: 2, TargetID: 100, Amount: 10, Attack: attack(2, 100, 21)}},
+			},
+		},
+		{
+			name: "hp without damage does not consume an attack",
+			steps: []attributionStep{
+				{ms: 0, attack: ptr(attack(1, 100, 11))},
+				{ms: 100, hp: hp(packet.HPData{TargetID: 100, Prev: 50, Current: 80})},
+				{ms: 200, hp: hp(hpDrop(100, 10)), want: &Damage{AttackerID: 1, TargetID: 100, Amount: 10, Attack: attack(1, 100, 11)}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributor := NewAttributor(2 * time.Second)
+
+			for i, step := range tt.steps {
+				ts := at(step.ms)
+
+				if step.attack != nil {
+					attributor.AddAttack(ts, *step.attack)
+					continue
+				}
+
+				got, ok := attributor.AddHP(ts, *step.hp)
+				if step.want == nil {
+					if ok {
+						t.Fatalf("step %d: AddHP = %+v, want no damage", i, got)
+					}
+					continue
+				}
+
+				want := *step.want
+				want.Timestamp = ts
+				if !ok || got != want {
+					t.Fatalf("step %d: AddHP = %+v, %v, want %+v", i, got, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAttributorBoundsPendingAttacks(t *testing.T) {
+	attributor := NewAttributor(2 * time.Second)
+
+	for i := range maxPendingAttacksPerTarget + 10 {
+		attributor.AddAttack(at(i), attack(1, 100, uint32(i)))
+	}
+
+	if pending := len(attributor.pending[100]); pending != maxPendingAttacksPerTarget {
+		t.Fatalf("pending attacks = %d, want %d", pending, maxPendingAttacksPerTarget)
+	}
+
+	// 한도를 넘으면 가장 오래된 공격부터 버립니다
+	damage, _ := attributor.AddHP(at(500), hpDrop(100, 1))
+	if damage.Attack.Key1 != 10 {
+		t.Fatalf("matched attack %d, want 10", damage.Attack.Key1)
+	}
+}
+
+func TestAttributorSweepsIdleTargets(t *testing.T) {
+	attributor := NewAttributor(2 * time.Second)
+
+	attributor.AddAttack(at(0), attack(1, 100, 11))
+	attributor.AddAttack(at(5000), attack(1, 200, 21))
+
+	if _, ok := attributor.pending[100]; ok {
+		t.Fatal("expired queue of an idle target was not swept")
+	}
+	if len(attributor.pending[200]) != 1 {
+		t.Fatalf("pending = %v, want only target 200", attributor.pending)
+	}
+}
@@ -0,0 +1,175 @@
+package combat
+
+import (
+	"sort"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// HP 기록이 없으면 추적을 중단하는 시간
+const hpTargetTTL = 5 * time.Minute
+
+// PhaseMark 대상의 HP가 기준 비율 아래로 내려간 시점
+type PhaseMark struct {
+	Threshold float64       // 0~1 사이의 HP 비율
+	Reached   time.Time     // 기준 아래로 내려간 캡처 시각
+	Elapsed   time.Duration // 첫 HP 기록부터 걸린 시간
+}
+
+// TargetHP 적대 대상 하나의 HP 진행 상황 스냅샷
+type TargetHP struct {
+	TargetID   uint32
+	MaxHP      uint32 // 관측한 가장 높은 HP
+	Current    uint32
+	Remaining  float64       // 남은 HP 비율 (0~1)
+	DamageRate float64       // 초당 HP 감소량
+	TimeToKill time.Duration // 예상 처치 시간. 추정할 수 없으면 0
+	FirstSeen  time.Time
+	LastUpdate time.Time
+	Phases     []PhaseMark // 지나간 단계 기준들 (높은 비율부터)
+}
+
+type hpSample struct {
+	timestamp time.Time
+	current   uint32
+}
+
+type hpTarget struct {
+	maxHP      uint32
+	current    uint32
+	firstSeen  time.Time
+	lastUpdate time.Time
+	samples    []hpSample // rateWindow 안의 HP 기록
+	phases     []PhaseMark
+}
+
+// HPTracker 적대 대상별 최대 HP, 남은 비율, 감소 속도와 단계 진입 시점을 추적합니다.
+// 시각은 캡처 시각을 사용하며, 고루틴 안전하지 않습니다.
+type HPTracker struct {
+	thresholds []float64
+	rateWindow time.Duration
+	targets    map[uint32]*hpTarget
+	lastSweep  time.Time
+}
+
+// NewHPTracker thresholds는 단계 기준 HP 비율(예: 0.75, 0.5, 0.25)입니다
+func NewHPTracker(thresholds []float64, rateWindow time.Duration) *HPTracker {
+	sorted := append([]float64(nil), thresholds...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+
+	return &HPTracker{
+		thresholds: sorted,
+		rateWindow: rateWindow,
+		targets:    make(map[uint32]*hpTarget),
+	}
+}
+
+// Observe HP 변화를 기록하고 이번에 새로 지난 단계 기준들을 반환합니다
+func (t *HPTracker) Observe(timestamp time.Time, hp packet.HPData) []PhaseMark {
+	t.sweep(timestamp)
+
+	target, ok := t.targets[hp.TargetID]
+	if !ok || (target.current == 0 && hp.Current > 0) {
+		// 처음 보거나 처치 후 다시 나타난 대상은 새로 추적합니다
+		target = &hpTarget{firstSeen: timestamp}
+		t.targets[hp.TargetID] = target
+	}
+
+	target.maxHP = max(target.maxHP, hp.Prev, hp.Current)
+	target.current = hp.Current
+	target.lastUpdate = maxTime(target.lastUpdate, timestamp)
+
+	target.samples = append(target.samples, hpSample{timestamp: timestamp, current: hp.Current})
+	cutoff := target.lastUpdate.Add(-t.rateWindow)
+	expired := 0
+	for expired < len(target.samples)-1 && target.samples[expired].timestamp.Before(cutoff) {
+		expired++
+	}
+	target.samples = target.samples[expired:]
+
+	var crossed []PhaseMark
+	remaining := target.remaining()
+	for _, threshold := range t.thresholds[len(target.phases):] {
+		if remaining > threshold {
+			break
+		}
+
+		mark := PhaseMark{
+			Threshold: threshold,
+			Reached:   timestamp,
+			Elapsed:   timestamp.Sub(target.firstSeen),
+		}
+		target.phases = append(target.phases, mark)
+		crossed = append(crossed, mark)
+	}
+
+	return crossed
+}
+
+// Snapshot 대상의 마지막 HP 기록 기준 진행 상황을 반환합니다
+func (t *HPTracker) Snapshot(targetID uint32) (TargetHP, bool) {
+	target, ok := t.targets[targetID]
+	if !ok {
+		return TargetHP{}, false
+	}
+
+	snapshot := TargetHP{
+		TargetID:   targetID,
+		MaxHP:      target.maxHP,
+		Current:    target.current,
+		Remaining:  target.remaining(),
+		DamageRate: target.damageRate(),
+		FirstSeen:  target.firstSeen,
+		LastUpdate: target.lastUpdate,
+		Phases:     append([]PhaseMark(nil), target.phases...),
+	}
+
+	if snapshot.DamageRate > 0 {
+		snapshot.TimeToKill = time.Duration(float64(target.current) / snapshot.DamageRate * float64(time.Second))
+	}
+
+	return snapshot, true
+}
+
+// Sweep now 기준으로 오래 갱신되지 않은 대상을 정리합니다
+func (t *HPTracker) Sweep(now time.Time) {
+	t.lastSweep = now
+
+	for id, target := range t.targets {
+		if now.Sub(target.lastUpdate) > hpTargetTTL {
+			delete(t.targets, id)
+		}
+	}
+}
+
+// sweep 마지막 정리 후 hpTargetTTL이 지났으면 Sweep을 호출합니다
+func (t *HPTracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < hpTargetTTL {
+		return
+	}
+
+	t.Sweep(now)
+}
+
+func (h *hpTarget) remaining() float64 {
+	if h.maxHP == 0 {
+		return 0
+	}
+	return float64(h.current) / float64(h.maxHP)
+}
+
+// damageRate rateWindow 안에서 HP가 줄어든 속도를 초 단위로 계산합니다
+func (h *hpTarget) damageRate() float64 {
+	if len(h.samples) < 2 {
+		return 0
+	}
+
+	first, last := h.samples[0], h.samples[len(h.samples)-1]
+	if last.current >= first.current {
+		return 0
+	}
+
+	elapsed := max(last.timestamp.Sub(first.timestamp), minDPSDuration)
+	return float64(first.current-last.current) / elapsed.Seconds()
+}
@@ -0,0 +1,93 @@
+package combat
+
+import (
+	"testing"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+func hpData(targetID, prev, current uint32) packet.HPData {
+	return hpEvent(0, targetID, prev, current).Payload.(packet.HPData)
+}
+
+func TestHPTrackerPhasesAndRate(t *testing.T) {
+	tracker := NewHPTracker([]float64{0.25, 0.75, 0.5}, 10*time.Second)
+
+	if marks := tracker.Observe(at(0), hpData(100, 1000, 1000)); len(marks) != 0 {
+		t.Fatalf("full HP crossed %v", marks)
+	}
+
+	// 한 번에 두 단계를 지나면 높은 비율부터 모두 반환합니다
+	marks := tracker.Observe(at(5000), hpData(100, 1000, 500))
+	if len(marks) != 2 || marks[0].Threshold != 0.75 || marks[1].Threshold != 0.5 || marks[1].Elapsed != 5*time.Second {
+		t.Fatalf("marks = %+v, want 0.75 and 0.5 after 5s", marks)
+	}
+
+	if marks := tracker.Observe(at(6000), hpData(100, 500, 400)); len(marks) != 0 {
+		t.Fatalf("marks = %+v, want none", marks)
+	}
+
+	snapshot, ok := tracker.Snapshot(100)
+	if !ok {
+		t.Fatal("target 100 not tracked")
+	}
+
+	// 10초 창 안의 첫 기록(1000)부터 마지막 기록(400)까지 6초
+	if snapshot.MaxHP != 1000 || snapshot.Current != 400 || snapshot.Remaining != 0.4 || snapshot.DamageRate != 100 || snapshot.TimeToKill != 4*time.Second {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+	if len(snapshot.Phases) != 2 {
+		t.Fatalf("phases = %+v", snapshot.Phases)
+	}
+}
+
+func TestHPTrackerRespawnResets(t *testing.T) {
+	tracker := NewHPTracker([]float64{0.5}, 10*time.Second)
+
+	tracker.Observe(at(0), hpData(100, 1000, 400))
+	tracker.Observe(at(1000), hpData(100, 400, 0))
+
+	// 처치 후 다시 HP가 생기면 새 대상으로 봅니다
+	marks := tracker.Observe(at(60000), hpData(100, 2000, 2000))
+	snapshot, _ := tracker.Snapshot(100)
+	if len(marks) != 0 || len(snapshot.Phases) != 0 || snapshot.MaxHP != 2000 || !snapshot.FirstSeen.Equal(at(60000)) {
+		t.Fatalf("respawned target = %+v, marks %+v", snapshot, marks)
+	}
+}
+
+func TestHPTrackerSweepsOnObserve(t *testing.T) {
+	tracker := NewHPTracker(nil, 10*time.Second)
+
+	tracker.Observe(at(0), hpData(100, 1000, 900))
+	tracker.Observe(at(1000), hpData(101, 1000, 900))
+
+	// 다른 대상의 HP 기록만으로도 오래된 대상이 정리되어야 합니다
+	later := int((hpTargetTTL + time.Minute) / time.Millisecond)
+	tracker.Observe(at(later), hpData(101, 900, 800))
+
+	if _, ok := tracker.Snapshot(100); ok {
+		t.Fatal("idle target 100 was not swept")
+	}
+	if _, ok := tracker.Snapshot(101); !ok {
+		t.Fatal("active target 101 was swept")
+	}
+}
+
+func TestEngineTickSweepsHP(t *testing.T) {
+	config := DefaultConfig()
+	config.WallClock = true
+	engine, _ := newTestEngine(config)
+
+	startFight(engine, 0)
+	if _, ok := engine.TargetHP(100); !ok {
+		t.Fatal("target 100 HP not tracked")
+	}
+
+	// 조용한 라이브 세션에서도 주기적인 시계로 HP 기록이 정리되어야 합니다
+	engine.tick(engine.nowWall.Add(hpTargetTTL + time.Second))
+
+	if _, ok := engine.TargetHP(100); ok {
+		t.Fatal("idle target HP was not swept by the engine clock")
+	}
+}
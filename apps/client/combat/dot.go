@@ -0,0 +1,170 @@
+package combat
+
+import (
+	"sort"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// DoTStats 플레이어 한 명이 대상 하나에 건 스킬 하나의 지속 피해 통계
+type DoTStats struct {
+	Skill     string
+	TargetID  uint32
+	Instances int // 틱 간격이 gap을 넘지 않고 이어진 묶음 수
+	Ticks     int
+	Damage    uint64        // 귀속된 틱 피해량 합계
+	Active    time.Duration // 각 묶음의 첫 틱부터 마지막 틱까지 시간의 합
+	Uptime    float64       // 전투 시간 중 Active의 비율 (0~1)
+}
+
+// PlayerDoTs 플레이어 한 명의 지속 피해 통계 (피해량 내림차순)와 합계
+type PlayerDoTs struct {
+	UserID uint32
+	Ticks  int
+	Damage uint64
+	DoTs   []DoTStats
+}
+
+type dotKey struct {
+	sourceID uint32
+	targetID uint32
+	skill    string
+}
+
+type dotPair struct {
+	sourceID uint32
+	targetID uint32
+}
+
+type dotInstance struct {
+	start    time.Time
+	lastTick time.Time
+}
+
+type dotEntry struct {
+	stats DoTStats
+	open  *dotInstance
+}
+
+// DoTBreakdown 지속 피해 틱(dot1~dot4 플래그)을 시전자와 대상별로 묶어 집계합니다.
+// 틱의 키로 스킬을 찾지 못하면 같은 시전자가 같은 대상에게 마지막으로 적중시킨 직접 공격의 스킬로 봅니다.
+// 고루틴 안전하지 않습니다.
+type DoTBreakdown struct {
+	gap     time.Duration
+	entries map[dotKey]*dotEntry
+	latest  map[dotPair]*dotEntry // 피해량을 귀속할 마지막 틱의 묶음
+	applied map[dotPair]string    // 마지막 직접 공격의 스킬 이름
+}
+
+// NewDoTBreakdown gap보다 길게 틱이 끊기면 새 묶음으로 봅니다
+func NewDoTBreakdown(gap time.Duration) *DoTBreakdown {
+	return &DoTBreakdown{
+		gap:     gap,
+		entries: make(map[dotKey]*dotEntry),
+		latest:  make(map[dotPair]*dotEntry),
+		applied: make(map[dotPair]string),
+	}
+}
+
+// ObserveDirect 직접 공격의 스킬을 기록해 이후 틱의 스킬을 추정하는 데 사용합니다
+func (b *DoTBreakdown) ObserveDirect(name string, attack packet.AttackData) {
+	if name == UnknownSkill {
+		return
+	}
+	b.applied[dotPair{attack.UserID, attack.TargetID}] = name
+}
+
+// AddTick 지속 피해 틱 하나를 기록합니다
+func (b *DoTBreakdown) AddTick(timestamp time.Time, name string, attack packet.AttackData) {
+	key := b.key(name, attack.UserID, attack.TargetID)
+	entry, ok := b.entries[key]
+	if !ok {
+		entry = &dotEntry{stats: DoTStats{Skill: key.skill, TargetID: attack.TargetID}}
+		b.entries[key] = entry
+	}
+
+	if entry.open == nil || timestamp.Sub(entry.open.lastTick) > b.gap {
+		entry.close()
+		entry.open = &dotInstance{start: timestamp, lastTick: timestamp}
+		entry.stats.Instances++
+	}
+
+	entry.open.lastTick = maxTime(entry.open.lastTick, timestamp)
+	entry.stats.Ticks++
+	b.latest[dotPair{attack.UserID, attack.TargetID}] = entry
+}
+
+// AddDamage 지속 피해 틱에 귀속된 피해량을 피해를 낸 틱의 스킬(name) 묶음에 더합니다.
+// 그 묶음이 없을 때만 같은 시전자와 대상의 마지막 틱 묶음에 더합니다.
+func (b *DoTBreakdown) AddDamage(name string, damage Damage) {
+	entry, ok := b.entries[b.key(name, damage.AttackerID, damage.TargetID)]
+	if !ok {
+		entry, ok = b.latest[dotPair{damage.AttackerID, damage.TargetID}]
+	}
+
+	if ok {
+		entry.stats.Damage += uint64(damage.Amount)
+	}
+}
+
+// key 스킬을 찾지 못한 틱과 피해는 applied에 기록된 스킬의 묶음으로 보냅니다
+func (b *DoTBreakdown) key(name string, sourceID, targetID uint32) dotKey {
+	if name == UnknownSkill {
+		if applied, ok := b.applied[dotPair{sourceID, targetID}]; ok {
+			name = applied
+		}
+	}
+
+	return dotKey{sourceID, targetID, name}
+}
+
+// Snapshot duration 길이의 전투 기준 플레이어별 지속 피해 통계를 반환합니다
+func (b *DoTBreakdown) Snapshot(duration time.Duration) []PlayerDoTs {
+	players := make(map[uint32]*PlayerDoTs)
+	for key, entry := range b.entries {
+		stats := entry.stats
+		if entry.open != nil {
+			stats.Active += entry.open.lastTick.Sub(entry.open.start)
+		}
+		if duration > 0 {
+			stats.Uptime = min(stats.Active.Seconds()/duration.Seconds(), 1)
+		}
+
+		player, ok := players[key.sourceID]
+		if !ok {
+			player = &PlayerDoTs{UserID: key.sourceID}
+			players[key.sourceID] = player
+		}
+		player.Ticks += stats.Ticks
+		player.Damage += stats.Damage
+		player.DoTs = append(player.DoTs, stats)
+	}
+
+	snapshot := make([]PlayerDoTs, 0, len(players))
+	for _, player := range players {
+		sort.Slice(player.DoTs, func(i, j int) bool {
+			if player.DoTs[i].Damage != player.DoTs[j].Damage {
+				return player.DoTs[i].Damage > player.DoTs[j].Damage
+			}
+			if player.DoTs[i].Skill != player.DoTs[j].Skill {
+				return player.DoTs[i].Skill < player.DoTs[j].Skill
+			}
+			return player.DoTs[i].TargetID < player.DoTs[j].TargetID
+		})
+		snapshot = append(snapshot, *player)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].UserID < snapshot[j].UserID })
+	return snapshot
+}
+
+// close 열려 있는 묶음의 시간을 합계에 더합니다
+func (e *dotEntry) close() {
+	if e.open == nil {
+		return
+	}
+
+	e.stats.Active += e.open.lastTick.Sub(e.open.start)
+	e.open = nil
+}
@@ -0,0 +1,78 @@
+package combat
+
+import (
+	"testing"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+func dotAttack(userID, targetID, key1 uint32) packet.AttackData {
+	data := attack(userID, targetID, key1)
+	data.Flags = data.Flags.With(packet.FlagDot1)
+	return data
+}
+
+func dotStats(t *testing.T, players []PlayerDoTs, userID uint32) map[string]DoTStats {
+	t.Helper()
+
+	for _, player := range players {
+		if player.UserID == userID {
+			stats := make(map[string]DoTStats)
+			for _, dot := range player.DoTs {
+				stats[dot.Skill] = dot
+			}
+			return stats
+		}
+	}
+
+	t.Fatalf("no DoTs for player %d in %+v", userID, players)
+	return nil
+}
+
+func TestEngineSeparatesOverlappingDoTs(t *testing.T) {
+	engine, reports := newTestEngine(DefaultConfig())
+
+	startFight(engine, 0)
+	engine.Handle(castEvent(100, 1, "Poison", 21))
+	engine.Handle(castEvent(110, 1, "Burn", 22))
+
+	// 같은 플레이어가 같은 대상에 건 두 지속 피해의 틱이 번갈아 들어옵니다
+	for i := range 3 {
+		ms := 1000 + i*1000
+		engine.Handle(attackEvent(ms, dotAttack(1, 100, 21)))
+		engine.Handle(attackEvent(ms+100, dotAttack(1, 100, 22)))
+		engine.Handle(hpEvent(ms+200, 100, uint32(900-i*40), uint32(900-i*40-10)))
+		engine.Handle(hpEvent(ms+300, 100, uint32(890-i*40), uint32(890-i*40-30)))
+	}
+	engine.Flush()
+
+	if len(*reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(*reports))
+	}
+
+	stats := dotStats(t, (*reports)[0].DoTs, 1)
+	if poison := stats["Poison"]; poison.Ticks != 3 || poison.Damage != 30 {
+		t.Errorf("Poison = %+v, want 3 ticks and 30 damage", poison)
+	}
+	if burn := stats["Burn"]; burn.Ticks != 3 || burn.Damage != 90 {
+		t.Errorf("Burn = %+v, want 3 ticks and 90 damage", burn)
+	}
+}
+
+func TestDoTBreakdownFallsBack(t *testing.T) {
+	breakdown := NewDoTBreakdown(3 * time.Second)
+
+	// 키로 스킬을 찾지 못한 틱과 피해는 마지막 직접 공격의 스킬로 묶입니다
+	breakdown.ObserveDirect("Slash", attack(1, 100, 11))
+	breakdown.AddTick(at(0), UnknownSkill, dotAttack(1, 100, 99))
+	breakdown.AddDamage(UnknownSkill, Damage{AttackerID: 1, TargetID: 100, Amount: 5})
+
+	// 틱으로 본 적 없는 스킬의 피해는 마지막 틱의 묶음으로 갑니다
+	breakdown.AddDamage("Burn", Damage{AttackerID: 1, TargetID: 100, Amount: 7})
+
+	stats := dotStats(t, breakdown.Snapshot(time.Second), 1)
+	if len(stats) != 1 || stats["Slash"].Ticks != 1 || stats["Slash"].Damage != 12 {
+		t.Fatalf("DoTs = %+v, want 12 damage on Slash", stats)
+	}
+}
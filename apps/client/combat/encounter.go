@@ -0,0 +1,217 @@
+package combat
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// EndReason 전투가 끝난 이유
+type EndReason string
+
+const (
+	EndReasonKilled       EndReason = "target_killed" // 주 대상의 HP가 0이 됨
+	EndReasonIdle         EndReason = "idle"          // 일정 시간 동안 공격이 없음
+	EndReasonCaptureEnded EndReason = "capture_ended" // 캡처가 끝나 강제로 종료함
+	EndReasonReset        EndReason = "reset"         // 재생을 처음부터 다시 시작해 강제로 종료함
+)
+
+// EncounterReport 전투 한 번의 요약
+type EncounterReport struct {
+	ID            int
+	Start         time.Time
+	End           time.Time
+	Duration      time.Duration
+	MainTarget    uint32
+	Participants  []uint32
+	TotalDamage   uint64
+	EndReason     EndReason // 진행 중이면 비어 있습니다
+	Meter         MeterSnapshot
+	Skills        []PlayerSkills    // 직접 공격만 집계합니다
+	DoTs          []PlayerDoTs      // 지속 피해 틱
+	Entities      map[uint32]Entity // 참여자와 주 대상의 엔티티 정보
+	TargetHP      TargetHP          // 주 대상의 HP 진행 상황. HP 기록이 없으면 MaxHP가 0입니다
+	LocalPlayerID uint32            // 로컬 플레이어 ID. 알 수 없으면 0
+}
+
+// Label 보고서에 표시할 id의 이름을 반환합니다
+func (r EncounterReport) Label(id uint32) string {
+	label := fmt.Sprintf("%d", id)
+	if entity, ok := r.Entities[id]; ok {
+		label = entity.Label()
+	}
+
+	if id != 0 && id == r.LocalPlayerID {
+		label += " (me)"
+	}
+	return label
+}
+
+type encounter struct {
+	id           int
+	start        time.Time
+	lastActivity time.Time
+	mainTarget   uint32
+	targetDamage map[uint32]uint64
+	participants map[uint32]struct{}
+	meter        *Meter
+	skills       *SkillBreakdown
+	dots         *DoTBreakdown
+}
+
+// EncounterTracker 공격과 HP 이벤트로 전투의 시작과 끝을 감지합니다.
+// 시각은 캡처 시각을 사용하며, 고루틴 안전하지 않습니다.
+type EncounterTracker struct {
+	idleTimeout time.Duration
+	dpsWindows  []time.Duration
+	nextID      int
+	dotGap      time.Duration
+	active      *encounter
+	isHostile   func(id uint32) bool
+}
+
+// NewEncounterTracker isHostile로 적대 대상을 판별하는 전투 감지기를 만듭니다
+func NewEncounterTracker(idleTimeout time.Duration, dpsWindows []time.Duration, dotGap time.Duration, isHostile func(id uint32) bool) *EncounterTracker {
+	return &EncounterTracker{
+		idleTimeout: idleTimeout,
+		dpsWindows:  dpsWindows,
+		dotGap:      dotGap,
+		nextID:      1,
+		isHostile:   isHostile,
+	}
+}
+
+// ObserveAttack 적대 대상에 대한 첫 공격이면 새 전투를 시작합니다
+func (t *EncounterTracker) ObserveAttack(timestamp time.Time, attack packet.AttackData) {
+	if !t.isHostile(attack.TargetID) {
+		return
+	}
+
+	if t.active == nil {
+		t.active = &encounter{
+			id:           t.nextID,
+			start:        timestamp,
+			mainTarget:   attack.TargetID,
+			targetDamage: make(map[uint32]uint64),
+			participants: make(map[uint32]struct{}),
+			meter:        NewMeter(t.dpsWindows),
+			skills:       NewSkillBreakdown(),
+			dots:         NewDoTBreakdown(t.dotGap),
+		}
+		t.nextID++
+	}
+
+	t.active.participants[attack.UserID] = struct{}{}
+	t.active.lastActivity = maxTime(t.active.lastActivity, timestamp)
+}
+
+// ObserveDamage 진행 중인 전투에 피해를 기록합니다
+func (t *EncounterTracker) ObserveDamage(damage Damage) {
+	if t.active == nil || !t.isHostile(damage.TargetID) {
+		return
+	}
+
+	t.active.meter.Add(damage)
+	t.active.lastActivity = maxTime(t.active.lastActivity, damage.Timestamp)
+
+	t.active.targetDamage[damage.TargetID] += uint64(damage.Amount)
+	if t.active.targetDamage[damage.TargetID] > t.active.targetDamage[t.active.mainTarget] {
+		t.active.mainTarget = damage.TargetID
+	}
+}
+
+// ObserveHP 주 대상의 HP가 0이 되면 전투를 끝내고 보고서를 반환합니다
+func (t *EncounterTracker) ObserveHP(timestamp time.Time, hp packet.HPData) (EncounterReport, bool) {
+	if t.active == nil || hp.TargetID != t.active.mainTarget || hp.Current != 0 {
+		return EncounterReport{}, false
+	}
+
+	return t.end(timestamp, EndReasonKilled), true
+}
+
+// Advance now까지 공격이 없던 시간이 idleTimeout을 넘으면 전투를 끝냅니다
+func (t *EncounterTracker) Advance(now time.Time) (EncounterReport, bool) {
+	if t.active == nil || now.Sub(t.active.lastActivity) < t.idleTimeout {
+		return EncounterReport{}, false
+	}
+
+	return t.end(t.active.lastActivity, EndReasonIdle), true
+}
+
+// Flush 진행 중인 전투를 강제로 끝냅니다
+func (t *EncounterTracker) Flush() (EncounterReport, bool) {
+	return t.Abort(EndReasonCaptureEnded)
+}
+
+// Abort 진행 중인 전투를 reason으로 강제로 끝냅니다
+func (t *EncounterTracker) Abort(reason EndReason) (EncounterReport, bool) {
+	if t.active == nil {
+		return EncounterReport{}, false
+	}
+
+	return t.end(t.active.lastActivity, reason), true
+}
+
+// Active 진행 중인 전투의 ID와 스킬 통계를 반환합니다
+func (t *EncounterTracker) Active() (int, *SkillBreakdown, bool) {
+	if t.active == nil {
+		return 0, nil, false
+	}
+
+	return t.active.id, t.active.skills, true
+}
+
+// ActiveDoTs 진행 중인 전투의 지속 피해 통계를 반환합니다
+func (t *EncounterTracker) ActiveDoTs() (*DoTBreakdown, bool) {
+	if t.active == nil {
+		return nil, false
+	}
+
+	return t.active.dots, true
+}
+
+// Current 진행 중인 전투의 now 시각 기준 보고서를 반환합니다
+func (t *EncounterTracker) Current(now time.Time) (EncounterReport, bool) {
+	if t.active == nil {
+		return EncounterReport{}, false
+	}
+
+	return t.active.report(now, ""), true
+}
+
+func (t *EncounterTracker) end(end time.Time, reason EndReason) EncounterReport {
+	report := t.active.report(end, reason)
+	t.active = nil
+	return report
+}
+
+func (e *encounter) report(end time.Time, reason EndReason) EncounterReport {
+	participants := make([]uint32, 0, len(e.participants))
+	for id := range e.participants {
+		participants = append(participants, id)
+	}
+	sort.Slice(participants, func(i, j int) bool { return participants[i] < participants[j] })
+
+	return EncounterReport{
+		ID:           e.id,
+		Start:        e.start,
+		End:          end,
+		Duration:     end.Sub(e.start),
+		MainTarget:   e.mainTarget,
+		Participants: participants,
+		TotalDamage:  e.meter.Total(),
+		EndReason:    reason,
+		Meter:        e.meter.Snapshot(end),
+		Skills:       e.skills.Snapshot(),
+		DoTs:         e.dots.Snapshot(end.Sub(e.start)),
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
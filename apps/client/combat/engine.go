@@ -0,0 +1,372 @@
+package combat
+
+import (
+	"sync"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// Config 전투 분석 설정
+type Config struct {
+	AttributionWindow time.Duration   // HP 감소와 공격을 연결할 때 허용하는 최대 시간 차
+	DPSWindows        []time.Duration // 슬라이딩 윈도우 DPS 구간
+	IdleTimeout       time.Duration   // 공격이 없으면 전투가 끝난 것으로 보는 시간
+	PhaseThresholds   []float64       // HP 단계 기준 비율 (높은 비율부터)
+	HPRateWindow      time.Duration   // HP 감소 속도와 예상 처치 시간을 계산할 구간
+	DoTGap            time.Duration   // 지속 피해 틱이 이보다 길게 끊기면 새로 건 것으로 봅니다
+
+	// 라이브 캡처처럼 이벤트가 끊겨도 시간이 흐르는 경우 true로 설정합니다.
+	// Run이 마지막 이벤트 이후 흐른 실제 시간만큼 시계를 진행해 조용한 동안에도 전투 종료를 판단합니다.
+	WallClock bool
+}
+
+// Run이 실제 시간으로 시계를 진행하는 주기
+const engineTickInterval = time.Second
+
+// DefaultConfig 기본 전투 분석 설정을 반환합니다
+func DefaultConfig() Config {
+	return Config{
+		AttributionWindow: 2 * time.Second,
+		DPSWindows:        []time.Duration{5 * time.Second, 30 * time.Second},
+		IdleTimeout:       15 * time.Second,
+		PhaseThresholds:   []float64{0.75, 0.5, 0.25},
+		HPRateWindow:      10 * time.Second,
+		DoTGap:            3 * time.Second,
+	}
+}
+
+// Engine 패킷 이벤트를 받아 전투 정보를 계산합니다.
+// 모든 시각은 이벤트의 캡처 시각을 기준으로 하므로 pcap 재생과 라이브 캡처의 결과가 같습니다.
+type Engine struct {
+	mu      sync.Mutex
+	config  Config
+	now     time.Time
+	nowWall time.Time // now가 마지막으로 진행된 실제 시각
+
+	attributor *Attributor
+	meter      *Meter
+	encounters *EncounterTracker
+	skills     *skillResolver
+	entities   *EntityRegistry
+	hp         *HPTracker
+
+	localPlayer uint32 // 이벤트에 실려 온 로컬 플레이어 ID
+
+	damageHandlers    []func(Damage)
+	encounterHandlers []func(EncounterReport)
+	phaseHandlers     []func(uint32, PhaseMark)
+}
+
+func NewEngine(config Config) *Engine {
+	e := &Engine{config: config}
+	e.encounters = NewEncounterTracker(config.IdleTimeout, config.DPSWindows, config.DoTGap, e.isHostile)
+	e.reset()
+	return e
+}
+
+// Reset 진행 중인 전투를 끝내고 모든 분석 상태를 비웁니다. 등록한 핸들러와 전투 ID는 유지됩니다.
+// PCAP 재생을 처음부터 다시 시작한 경우처럼 이후 이벤트가 이전 이벤트와 이어지지 않을 때 사용합니다.
+func (e *Engine) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.restart()
+}
+
+// restart 진행 중인 전투를 EndReasonReset으로 끝내고 상태를 비웁니다
+func (e *Engine) restart() {
+	if report, ok := e.encounters.Abort(EndReasonReset); ok {
+		e.emitEncounter(report)
+	}
+	e.reset()
+}
+
+// reset 전투를 제외한 분석 상태를 새로 만듭니다
+func (e *Engine) reset() {
+	e.now, e.nowWall = time.Time{}, time.Time{}
+	e.localPlayer = 0
+
+	e.attributor = NewAttributor(e.config.AttributionWindow)
+	e.meter = NewMeter(e.config.DPSWindows)
+	e.skills = newSkillResolver()
+	e.entities = NewEntityRegistry()
+	e.hp = NewHPTracker(e.config.PhaseThresholds, e.config.HPRateWindow)
+}
+
+// isHostile Reset으로 바뀌는 엔티티 목록을 전투 감지기가 따라가도록 현재 목록에 묻습니다
+func (e *Engine) isHostile(id uint32) bool {
+	return e.entities.IsHostile(id)
+}
+
+// OnDamage 귀속된 피해 기록을 받을 핸들러를 등록합니다.
+// 핸들러는 엔진 잠금 안에서 호출되므로 엔진 메서드를 다시 호출하면 안 됩니다.
+func (e *Engine) OnDamage(handler func(Damage)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.damageHandlers = append(e.damageHandlers, handler)
+}
+
+// OnEncounterEnd 전투가 끝날 때 보고서를 받을 핸들러를 등록합니다.
+// 핸들러는 엔진 잠금 안에서 호출되므로 엔진 메서드를 다시 호출하면 안 됩니다.
+func (e *Engine) OnEncounterEnd(handler func(EncounterReport)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.encounterHandlers = append(e.encounterHandlers, handler)
+}
+
+// OnPhase 적대 대상의 HP가 단계 기준 아래로 내려갈 때마다 호출될 핸들러를 등록합니다.
+// 핸들러는 엔진 잠금 안에서 호출되므로 엔진 메서드를 다시 호출하면 안 됩니다.
+func (e *Engine) OnPhase(handler func(targetID uint32, mark PhaseMark)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.phaseHandlers = append(e.phaseHandlers, handler)
+}
+
+// Run events 채널이 닫힐 때까지 이벤트를 처리하고, 닫히면 진행 중인 전투를 끝냅니다.
+// WallClock이 설정되어 있으면 이벤트가 없는 동안에도 주기적으로 시계를 진행합니다.
+// PCAP 파일은 캡처 시각만으로 시간이 흐르므로 다음 이벤트나 Flush에서 전투 종료를 판단합니다.
+func (e *Engine) Run(events <-chan packet.Event) {
+	var ticks <-chan time.Time
+	if e.config.WallClock {
+		ticker := time.NewTicker(engineTickInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				e.Flush()
+				return
+			}
+			e.Handle(event)
+		case wall := <-ticks:
+			e.tick(wall)
+		}
+	}
+}
+
+// tick 마지막 이벤트 이후 흐른 실제 시간만큼 진행한 캡처 시각 기준으로 유휴 전투를 끝냅니다.
+// 이벤트의 시각(now)은 바꾸지 않으므로 이후 이벤트 처리에는 영향이 없습니다.
+func (e *Engine) tick(wall time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.now.IsZero() {
+		return
+	}
+
+	if elapsed := wall.Sub(e.nowWall); elapsed > 0 {
+		e.advance(e.now.Add(elapsed))
+	}
+}
+
+// Flush 진행 중인 전투를 끝내고 보고서를 전달합니다
+func (e *Engine) Flush() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if report, ok := e.encounters.Flush(); ok {
+		e.emitEncounter(report)
+	}
+}
+
+// Handle 이벤트 하나를 처리합니다
+func (e *Engine) Handle(event packet.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := event.Payload.(packet.StreamReset); ok {
+		e.restart()
+		return
+	}
+
+	if event.Timestamp.After(e.now) {
+		e.now = event.Timestamp
+		e.nowWall = time.Now()
+	}
+
+	if event.LocalPlayerID != 0 && event.LocalPlayerID != e.localPlayer {
+		e.localPlayer = event.LocalPlayerID
+		e.entities.SetKind(e.localPlayer, EntityPlayer)
+	}
+
+	e.advance(e.now)
+
+	e.entities.Observe(event)
+
+	switch payload := event.Payload.(type) {
+	case packet.ActionData:
+		cast := e.skills.addCast(event.Timestamp, payload)
+		e.countCast(cast)
+
+	case packet.AttackData:
+		e.attributor.AddAttack(event.Timestamp, payload)
+		e.encounters.ObserveAttack(event.Timestamp, payload)
+
+		if _, breakdown, ok := e.encounters.Active(); ok {
+			if cast, ok := e.skills.resolve(payload); ok {
+				// 전투 시작 직전에 사용한 스킬도 해당 전투의 사용 횟수에 포함합니다
+				e.countCast(cast)
+			}
+
+			name := e.skills.skillName(payload)
+			dots, _ := e.encounters.ActiveDoTs()
+			if payload.Flags.IsDoT() {
+				dots.AddTick(event.Timestamp, name, payload)
+			} else {
+				breakdown.AddHit(name, payload)
+				dots.ObserveDirect(name, payload)
+			}
+		}
+
+	case packet.HPData:
+		if e.entities.IsHostile(payload.TargetID) {
+			for _, mark := range e.hp.Observe(event.Timestamp, payload) {
+				e.emitPhase(payload.TargetID, mark)
+			}
+		}
+
+		if damage, ok := e.attributor.AddHP(event.Timestamp, payload); ok {
+			e.meter.Add(damage)
+			e.encounters.ObserveDamage(damage)
+
+			if _, breakdown, ok := e.encounters.Active(); ok && !damage.Unattributed {
+				if damage.Attack.Flags.IsDoT() {
+					dots, _ := e.encounters.ActiveDoTs()
+					dots.AddDamage(e.skills.skillName(damage.Attack), damage)
+				} else {
+					breakdown.AddDamage(e.skills.skillName(damage.Attack), damage)
+				}
+			}
+
+			e.emitDamage(damage)
+		}
+
+		if report, ok := e.encounters.ObserveHP(event.Timestamp, payload); ok {
+			e.emitEncounter(report)
+		}
+	}
+}
+
+// advance now까지 공격이 없던 전투를 끝내고 오래된 HP 기록과 엔티티를 정리합니다.
+// 이벤트마다, 그리고 WallClock이면 이벤트가 없는 동안에도 주기적으로 호출됩니다. 각 정리는 자체 주기로 제한됩니다.
+// 공격 대기열과 스킬 키는 추정 시각으로 지우면 늦게 도착한 이벤트를 놓칠 수 있으므로 이벤트 시각으로만 정리합니다.
+func (e *Engine) advance(now time.Time) {
+	if report, ok := e.encounters.Advance(now); ok {
+		e.emitEncounter(report)
+	}
+
+	e.hp.sweep(now)
+	e.entities.sweep(now)
+}
+
+// MeterSnapshot 마지막으로 처리한 이벤트의 캡처 시각 기준 DPS 미터 상태를 반환합니다
+func (e *Engine) MeterSnapshot() MeterSnapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.meter.Snapshot(e.now)
+}
+
+// countCast 진행 중인 전투에 아직 집계되지 않은 스킬 사용을 집계합니다
+func (e *Engine) countCast(cast *skillCast) {
+	id, breakdown, ok := e.encounters.Active()
+	if !ok || cast.counted == id {
+		return
+	}
+
+	cast.counted = id
+	breakdown.AddCast(cast.userID, cast.name)
+}
+
+// CurrentEncounter 진행 중인 전투의 현재 보고서를 반환합니다
+func (e *Engine) CurrentEncounter() (EncounterReport, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report, ok := e.encounters.Current(e.now)
+	if ok {
+		e.annotate(&report)
+	}
+	return report, ok
+}
+
+// Entity id의 엔티티 정보를 반환합니다
+func (e *Engine) Entity(id uint32) (Entity, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.entities.Lookup(id)
+}
+
+// Entities 관측한 모든 엔티티를 반환합니다
+func (e *Engine) Entities() []Entity {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.entities.Entities()
+}
+
+// SetEntityName 엔티티 이름을 지정합니다
+func (e *Engine) SetEntityName(id uint32, name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.entities.SetName(id, name)
+}
+
+// LocalPlayer 로컬 플레이어 ID를 반환합니다. 알 수 없으면 0입니다.
+func (e *Engine) LocalPlayer() uint32 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.localPlayer
+}
+
+// TargetHP 적대 대상의 HP 진행 상황을 반환합니다
+func (e *Engine) TargetHP(targetID uint32) (TargetHP, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.hp.Snapshot(targetID)
+}
+
+// annotate 보고서에 등장하는 ID들의 엔티티 정보와 주 대상의 HP 진행 상황을 채웁니다
+func (e *Engine) annotate(report *EncounterReport) {
+	ids := append([]uint32{report.MainTarget}, report.Participants...)
+	for _, player := range report.DoTs {
+		for _, dot := range player.DoTs {
+			ids = append(ids, dot.TargetID)
+		}
+	}
+
+	report.Entities = e.entities.Subset(ids...)
+	report.TargetHP, _ = e.hp.Snapshot(report.MainTarget)
+	report.LocalPlayerID = e.localPlayer
+}
+
+func (e *Engine) emitEncounter(report EncounterReport) {
+	e.annotate(&report)
+
+	for _, handler := range e.encounterHandlers {
+		handler(report)
+	}
+}
+
+func (e *Engine) emitPhase(targetID uint32, mark PhaseMark) {
+	for _, handler := range e.phaseHandlers {
+		handler(targetID, mark)
+	}
+}
+
+func (e *Engine) emitDamage(damage Damage) {
+	for _, handler := range e.damageHandlers {
+		handler(damage)
+	}
+}
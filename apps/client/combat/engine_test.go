@@ -0,0 +1,147 @@
+package combat
+
+import (
+	"testing"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+func castEvent(ms int, userID uint32, skill string, key1 uint32) packet.Event {
+	return packet.Event{Timestamp: at(ms), Payload: packet.ActionData{UserID: userID, SkillName: skill, Key1: key1}}
+}
+
+func attackEvent(ms int, data packet.AttackData) packet.Event {
+	return packet.Event{Timestamp: at(ms), Payload: data}
+}
+
+func hpEvent(ms int, targetID, prev, current uint32) packet.Event {
+	hp := packet.HPData{TargetID: targetID, Prev: prev, Current: current}
+	if prev > current {
+		hp.Damage = prev - current
+	}
+
+	return packet.Event{Timestamp: at(ms), Payload: hp}
+}
+
+// newTestEngine 전투 종료 보고서를 모으는 엔진을 만듭니다
+func newTestEngine(config Config) (*Engine, *[]EncounterReport) {
+	engine := NewEngine(config)
+
+	var reports []EncounterReport
+	engine.OnEncounterEnd(func(report EncounterReport) {
+		reports = append(reports, report)
+	})
+
+	return engine, &reports
+}
+
+// startFight 플레이어 1이 대상 100을 공격해 전투를 시작합니다
+func startFight(engine *Engine, ms int) {
+	engine.Handle(castEvent(ms, 1, "Slash", 11))
+	engine.Handle(attackEvent(ms+10, attack(1, 100, 11)))
+	engine.Handle(hpEvent(ms+20, 100, 1000, 900))
+}
+
+func TestEngineTickEndsIdleEncounter(t *testing.T) {
+	config := DefaultConfig()
+	config.WallClock = true
+	engine, reports := newTestEngine(config)
+
+	startFight(engine, 0)
+	wall := engine.nowWall
+
+	// 이벤트가 없어도 실제 시간이 흐르면 유휴 시간 뒤에 전투가 끝나야 합니다
+	engine.tick(wall.Add(config.IdleTimeout - time.Second))
+	if len(*reports) != 0 {
+		t.Fatalf("encounter ended %v before the idle timeout", (*reports)[0].End)
+	}
+
+	engine.tick(wall.Add(config.IdleTimeout))
+	if len(*reports) != 1 {
+		t.Fatalf("got %d reports after the idle timeout, want 1", len(*reports))
+	}
+
+	report := (*reports)[0]
+	if report.EndReason != EndReasonIdle || !report.End.Equal(at(20)) || report.TotalDamage != 100 {
+		t.Fatalf("report = %s at %v with %d damage", report.EndReason, report.End, report.TotalDamage)
+	}
+
+	// 틱은 이벤트 시각을 바꾸지 않습니다
+	if !engine.now.Equal(at(20)) {
+		t.Fatalf("engine clock moved to %v by tick", engine.now)
+	}
+}
+
+func TestEngineTickBeforeFirstEvent(t *testing.T) {
+	engine, reports := newTestEngine(DefaultConfig())
+
+	engine.tick(time.Now().Add(time.Hour))
+	if len(*reports) != 0 || !engine.now.IsZero() {
+		t.Fatalf("tick without events produced %d reports", len(*reports))
+	}
+}
+
+func TestEngineEventTimeEndsIdleEncounter(t *testing.T) {
+	config := DefaultConfig()
+	engine, reports := newTestEngine(config)
+
+	// 파일 재생에서는 다음 이벤트의 캡처 시각으로 유휴 시간을 판단합니다
+	startFight(engine, 0)
+	engine.Handle(hpEvent(20+int(config.IdleTimeout/time.Millisecond), 200, 50, 50))
+
+	if len(*reports) != 1 || (*reports)[0].EndReason != EndReasonIdle {
+		t.Fatalf("reports = %+v, want one idle report", *reports)
+	}
+}
+
+func TestEngineRunFlushesOnClose(t *testing.T) {
+	config := DefaultConfig()
+	config.WallClock = true
+	engine, reports := newTestEngine(config)
+
+	events := make(chan packet.Event, 4)
+	events <- castEvent(0, 1, "Slash", 11)
+	events <- attackEvent(10, attack(1, 100, 11))
+	events <- hpEvent(20, 100, 1000, 900)
+	close(events)
+
+	engine.Run(events)
+
+	if len(*reports) != 1 || (*reports)[0].EndReason != EndReasonCaptureEnded {
+		t.Fatalf("reports = %+v, want one capture_ended report", *reports)
+	}
+}
+
+func TestEngineStreamResetRestartsAnalysis(t *testing.T) {
+	engine, reports := newTestEngine(DefaultConfig())
+
+	startFight(engine, 60000)
+
+	// 재생을 처음부터 다시 시작하면 진행 중인 전투를 끝내고 이전 상태를 버립니다
+	engine.Handle(packet.Event{Timestamp: at(60020), Payload: packet.StreamReset{}})
+	if len(*reports) != 1 || (*reports)[0].EndReason != EndReasonReset || (*reports)[0].TotalDamage != 100 {
+		t.Fatalf("reports = %+v, want one reset report with 100 damage", *reports)
+	}
+	if _, ok := engine.CurrentEncounter(); ok {
+		t.Fatal("encounter still active after the reset")
+	}
+	if _, ok := engine.TargetHP(100); ok {
+		t.Fatal("target HP kept after the reset")
+	}
+	if !engine.now.IsZero() {
+		t.Fatalf("engine clock kept %v after the reset", engine.now)
+	}
+
+	// 이전보다 앞선 캡처 시각의 이벤트로 새 전투가 시작됩니다
+	startFight(engine, 0)
+	report, ok := engine.CurrentEncounter()
+	if !ok || report.ID != 2 || !report.Start.Equal(at(10)) || report.TotalDamage != 100 {
+		t.Fatalf("encounter after the reset = %+v, %v", report, ok)
+	}
+
+	engine.Reset()
+	if len(*reports) != 2 || (*reports)[1].EndReason != EndReasonReset {
+		t.Fatalf("Reset reports = %+v, want a second reset report", *reports)
+	}
+}
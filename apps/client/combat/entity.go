@@ -0,0 +1,235 @@
+package combat
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// 이 시간 동안 다시 관측되지 않은 엔티티는 잊습니다 (이름이나 분류를 직접 지정한 엔티티는 유지)
+const entityTTL = 10 * time.Minute
+
+// EntityKind 엔티티 분류
+type EntityKind uint8
+
+const (
+	EntityUnknown EntityKind = iota
+	EntityPlayer
+	EntityMonster
+	EntitySummon
+)
+
+func (k EntityKind) String() string {
+	switch k {
+	case EntityPlayer:
+		return "player"
+	case EntityMonster:
+		return "monster"
+	case EntitySummon:
+		return "summon"
+	default:
+		return "unknown"
+	}
+}
+
+// Entity 패킷에 등장한 ID 하나에 대해 알게 된 정보
+type Entity struct {
+	ID        uint32
+	Kind      EntityKind
+	Name      string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Casts     int    // 스킬 사용 횟수
+	Attacks   int    // 공격자로 등장한 횟수
+	Hits      int    // 공격 대상으로 등장한 횟수
+	MaxHP     uint32 // HP 이벤트에서 관측한 최대 HP
+
+	hitsByPlayers int // 플레이어에게 공격당한 횟수
+	hitsOnPlayers int // 플레이어를 공격한 횟수
+	kindFixed     bool
+}
+
+// Label 보고서에 표시할 이름. 이름을 모르면 분류와 ID를 사용합니다.
+func (e Entity) Label() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%s (%d)", e.Name, e.ID)
+	}
+	return fmt.Sprintf("%s %d", e.Kind, e.ID)
+}
+
+// EntityRegistry 패킷에서 관측한 ID를 플레이어, 몬스터, 소환수로 분류합니다.
+// 분류는 다음 휴리스틱을 따르며, SetKind로 직접 지정한 값이 우선합니다.
+//   - 스킬(ActionData)을 사용했거나 아이템을 획득한 ID는 플레이어
+//   - 플레이어에게 공격당했거나 플레이어를 공격한 ID는 몬스터
+//   - 그 외에는 알 수 없음. 첫 스킬 전에 기본 공격만 한 플레이어와 소환수는 구분할 수 없으므로
+//     소환수는 SetKind로만 지정합니다.
+//
+// entityTTL 동안 관측되지 않은 엔티티는 제거됩니다. 고루틴 안전하지 않습니다.
+type EntityRegistry struct {
+	entities  map[uint32]*Entity
+	lastSweep time.Time
+}
+
+func NewEntityRegistry() *EntityRegistry {
+	return &EntityRegistry{
+		entities: make(map[uint32]*Entity),
+	}
+}
+
+// Observe 이벤트에 등장한 ID들을 기록하고 분류를 갱신합니다
+func (r *EntityRegistry) Observe(event packet.Event) {
+	timestamp := event.Timestamp
+	r.sweep(timestamp)
+
+	switch payload := event.Payload.(type) {
+	case packet.ActionData:
+		caster := r.touch(payload.UserID, timestamp)
+		caster.Casts++
+		r.classify(caster)
+
+	case packet.AttackData:
+		r.observeHit(payload.UserID, payload.TargetID, timestamp)
+
+	case packet.SelfDamageData:
+		r.observeHit(payload.AttackerID, payload.VictimID, timestamp)
+
+	case packet.HPData:
+		target := r.touch(payload.TargetID, timestamp)
+		target.MaxHP = max(target.MaxHP, payload.Prev, payload.Current)
+
+	case packet.ItemData:
+		owner := r.touch(payload.OwnerID, timestamp)
+		r.setKind(owner, EntityPlayer)
+	}
+}
+
+// SetName 엔티티의 이름을 기록합니다 (이름을 담은 패킷이나 사용자 설정)
+func (r *EntityRegistry) SetName(id uint32, name string) {
+	r.ensure(id).Name = name
+}
+
+// SetKind 엔티티 분류를 직접 지정합니다. 이후 휴리스틱으로 바뀌지 않습니다.
+func (r *EntityRegistry) SetKind(id uint32, kind EntityKind) {
+	entity := r.ensure(id)
+	entity.Kind = kind
+	entity.kindFixed = true
+}
+
+// Lookup id의 엔티티 정보를 반환합니다
+func (r *EntityRegistry) Lookup(id uint32) (Entity, bool) {
+	entity, ok := r.entities[id]
+	if !ok {
+		return Entity{ID: id}, false
+	}
+	return *entity, true
+}
+
+// Kind id의 분류를 반환합니다
+func (r *EntityRegistry) Kind(id uint32) EntityKind {
+	if entity, ok := r.entities[id]; ok {
+		return entity.Kind
+	}
+	return EntityUnknown
+}
+
+// IsHostile 플레이어나 소환수로 알려지지 않은 ID를 적대 대상으로 봅니다.
+// 스킬 없이 기본 공격만 오가는 전투에서는 대상이 분류되지 않으므로 분류되지 않은 대상도 포함합니다.
+func (r *EntityRegistry) IsHostile(id uint32) bool {
+	switch r.Kind(id) {
+	case EntityPlayer, EntitySummon:
+		return false
+	default:
+		return true
+	}
+}
+
+// Entities 모든 엔티티를 ID 순으로 반환합니다
+func (r *EntityRegistry) Entities() []Entity {
+	list := make([]Entity, 0, len(r.entities))
+	for _, entity := range r.entities {
+		list = append(list, *entity)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Subset ids에 해당하는 엔티티들을 반환합니다 (보고서용)
+func (r *EntityRegistry) Subset(ids ...uint32) map[uint32]Entity {
+	subset := make(map[uint32]Entity, len(ids))
+	for _, id := range ids {
+		subset[id], _ = r.Lookup(id)
+	}
+	return subset
+}
+
+func (r *EntityRegistry) observeHit(sourceID, targetID uint32, timestamp time.Time) {
+	source := r.touch(sourceID, timestamp)
+	target := r.touch(targetID, timestamp)
+
+	source.Attacks++
+	target.Hits++
+
+	if source.Kind == EntityPlayer {
+		target.hitsByPlayers++
+	}
+
+	if target.Kind == EntityPlayer {
+		source.hitsOnPlayers++
+	}
+
+	r.classify(source)
+	r.classify(target)
+}
+
+func (r *EntityRegistry) classify(entity *Entity) {
+	switch {
+	case entity.Casts > 0:
+		r.setKind(entity, EntityPlayer)
+	case entity.Kind == EntityPlayer:
+		// 플레이어 분류는 유지합니다
+	case entity.hitsByPlayers > 0 || entity.hitsOnPlayers > 0:
+		r.setKind(entity, EntityMonster)
+	}
+}
+
+func (r *EntityRegistry) setKind(entity *Entity, kind EntityKind) {
+	if !entity.kindFixed {
+		entity.Kind = kind
+	}
+}
+
+// sweep entityTTL 동안 관측되지 않은 엔티티를 제거합니다. 직접 지정한 이름이나 분류는 유지합니다.
+func (r *EntityRegistry) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < entityTTL {
+		return
+	}
+	r.lastSweep = now
+
+	cutoff := now.Add(-entityTTL)
+	for id, entity := range r.entities {
+		if entity.LastSeen.Before(cutoff) && !entity.kindFixed && entity.Name == "" {
+			delete(r.entities, id)
+		}
+	}
+}
+
+func (r *EntityRegistry) touch(id uint32, timestamp time.Time) *Entity {
+	entity := r.ensure(id)
+	if entity.FirstSeen.IsZero() || timestamp.Before(entity.FirstSeen) {
+		entity.FirstSeen = timestamp
+	}
+	entity.LastSeen = maxTime(entity.LastSeen, timestamp)
+	return entity
+}
+
+func (r *EntityRegistry) ensure(id uint32) *Entity {
+	entity, ok := r.entities[id]
+	if !ok {
+		entity = &Entity{ID: id}
+		r.entities[id] = entity
+	}
+	return entity
+}
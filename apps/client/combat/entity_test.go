@@ -0,0 +1,184 @@
+package combat
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+func selfDamageEvent(ms int, attackerID, victimID, amount uint32) packet.Event {
+	return packet.Event{Timestamp: at(ms), Payload: packet.SelfDamageData{AttackerID: attackerID, VictimID: victimID, Damage: amount}}
+}
+
+func TestEntityClassification(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []packet.Event
+		want   map[uint32]EntityKind
+	}{
+		{
+			name: "caster is a player and its target a monster",
+			events: []packet.Event{
+				castEvent(0, 1, "Slash", 11),
+				attackEvent(10, attack(1, 100, 11)),
+			},
+			want: map[uint32]EntityKind{1: EntityPlayer, 100: EntityMonster},
+		},
+		{
+			name: "auto attack before the first cast stays unknown, not summon",
+			events: []packet.Event{
+				attackEvent(0, attack(2, 100, 0)),
+				castEvent(10, 1, "Slash", 11),
+				attackEvent(20, attack(1, 100, 11)),
+				attackEvent(30, attack(2, 100, 0)),
+			},
+			want: map[uint32]EntityKind{1: EntityPlayer, 2: EntityUnknown, 100: EntityMonster},
+		},
+		{
+			name: "auto attacking player becomes a player on the first cast",
+			events: []packet.Event{
+				castEvent(0, 1, "Slash", 11),
+				attackEvent(10, attack(1, 100, 11)),
+				attackEvent(20, attack(2, 100, 0)),
+				castEvent(30, 2, "Heal", 21),
+			},
+			want: map[uint32]EntityKind{1: EntityPlayer, 2: EntityPlayer, 100: EntityMonster},
+		},
+		{
+			name: "attacker of a player is a monster",
+			events: []packet.Event{
+				castEvent(0, 1, "Slash", 11),
+				selfDamageEvent(10, 200, 1, 30),
+			},
+			want: map[uint32]EntityKind{1: EntityPlayer, 200: EntityMonster},
+		},
+		{
+			name: "unknown attacker on an unknown target",
+			events: []packet.Event{
+				attackEvent(0, attack(3, 300, 0)),
+			},
+			want: map[uint32]EntityKind{3: EntityUnknown, 300: EntityUnknown},
+		},
+		{
+			name: "item owner is a player",
+			events: []packet.Event{
+				{Timestamp: at(0), Payload: packet.ItemData{OwnerID: 4, ItemID: 1}},
+			},
+			want: map[uint32]EntityKind{4: EntityPlayer},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewEntityRegistry()
+			for _, event := range tt.events {
+				registry.Observe(event)
+			}
+
+			for id, want := range tt.want {
+				if got := registry.Kind(id); got != want {
+					t.Errorf("Kind(%d) = %s, want %s", id, got, want)
+				}
+				hostile := want == EntityMonster || want == EntityUnknown
+				if got := registry.IsHostile(id); got != hostile {
+					t.Errorf("IsHostile(%d) = %v for %s", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEntitySetKindOverridesHeuristics(t *testing.T) {
+	registry := NewEntityRegistry()
+	registry.SetKind(5, EntitySummon)
+
+	registry.Observe(castEvent(0, 5, "Bite", 51))
+	registry.Observe(attackEvent(10, attack(5, 100, 51)))
+
+	if kind := registry.Kind(5); kind != EntitySummon {
+		t.Fatalf("Kind(5) = %s, want summon", kind)
+	}
+	if registry.IsHostile(5) {
+		t.Fatal("summon is hostile")
+	}
+}
+
+func TestEntityRegistryExpiresIdleEntities(t *testing.T) {
+	registry := NewEntityRegistry()
+
+	registry.Observe(castEvent(0, 1, "Slash", 11))
+	registry.Observe(attackEvent(10, attack(1, 100, 11)))
+	registry.SetName(100, "Boss")
+	registry.SetKind(7, EntityPlayer)
+	registry.Observe(attackEvent(20, attack(1, 101, 11)))
+
+	// 플레이어 1은 계속 보이고, 몬스터 101은 한동안 보이지 않습니다
+	later := int((entityTTL + time.Minute) / time.Millisecond)
+	registry.Observe(castEvent(later, 1, "Slash", 12))
+	registry.Observe(castEvent(later+int(entityTTL/time.Millisecond), 1, "Slash", 13))
+
+	if _, ok := registry.Lookup(101); ok {
+		t.Fatal("idle entity 101 was not expired")
+	}
+	for _, id := range []uint32{1, 7, 100} {
+		if _, ok := registry.Lookup(id); !ok {
+			t.Fatalf("entity %d was expired", id)
+		}
+	}
+}
+
+func TestEngineIgnoresAttacksOnPlayers(t *testing.T) {
+	engine, reports := newTestEngine(DefaultConfig())
+
+	// 플레이어로 알려진 대상에 대한 공격은 전투를 시작하지 않습니다
+	engine.Handle(castEvent(0, 1, "Slash", 11))
+	engine.Handle(attackEvent(10, attack(2, 1, 0)))
+	engine.Handle(hpEvent(20, 1, 1000, 900))
+
+	if _, ok := engine.CurrentEncounter(); ok {
+		t.Fatal("attack on a player started an encounter")
+	}
+
+	startFight(engine, 100)
+	if report, ok := engine.CurrentEncounter(); !ok || report.MainTarget != 100 {
+		t.Fatalf("encounter = %+v, %v, want one on target 100", report, ok)
+	}
+	if len(*reports) != 0 {
+		t.Fatalf("unexpected reports %+v", *reports)
+	}
+}
+
+func TestEngineBossKilledWithoutCasts(t *testing.T) {
+	engine, reports := newTestEngine(DefaultConfig())
+
+	var phases []PhaseMark
+	engine.OnPhase(func(targetID uint32, mark PhaseMark) {
+		phases = append(phases, mark)
+	})
+
+	// 스킬 사용 없이 기본 공격만으로 보스를 처치합니다
+	engine.Handle(attackEvent(0, attack(2, 100, 0)))
+	engine.Handle(hpEvent(10, 100, 1000, 600))
+	engine.Handle(attackEvent(20, attack(3, 100, 0)))
+	engine.Handle(hpEvent(30, 100, 600, 0))
+
+	if len(*reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(*reports))
+	}
+
+	report := (*reports)[0]
+	if report.EndReason != EndReasonKilled || report.MainTarget != 100 {
+		t.Fatalf("report ended by %q on %d, want killed on 100", report.EndReason, report.MainTarget)
+	}
+	if report.TotalDamage != 1000 {
+		t.Fatalf("TotalDamage = %d, want 1000", report.TotalDamage)
+	}
+	if !slices.Equal(report.Participants, []uint32{2, 3}) {
+		t.Fatalf("Participants = %v, want [2 3]", report.Participants)
+	}
+	if len(phases) != 3 {
+		t.Fatalf("got %d phase marks, want 3", len(phases))
+	}
+}
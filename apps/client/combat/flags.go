@@ -0,0 +1,125 @@
+package combat
+
+import "mogi-suction/client/packet"
+
+// Elements 속성별 피해 통계를 내는 원소 플래그
+var Elements = [...]packet.DamageFlag{
+	packet.FlagFire,
+	packet.FlagIce,
+	packet.FlagElectric,
+	packet.FlagPoison,
+	packet.FlagHoly,
+	packet.FlagDark,
+	packet.FlagBleed,
+	packet.FlagMind,
+}
+
+// UnknownFlags 의미가 밝혀지지 않아 피해량과의 상관관계를 추적하는 플래그
+var UnknownFlags = [...]packet.DamageFlag{packet.FlagWhat1, packet.FlagWhat05, packet.FlagWhat06}
+
+// UnknownFlagStats 미확인 플래그 하나가 켜진 타격의 통계
+type UnknownFlagStats struct {
+	Hits       int
+	DamageHits int
+	Damage     uint64
+}
+
+// AverageHit 플래그가 켜진 타격의 평균 피해량
+func (s UnknownFlagStats) AverageHit() float64 {
+	if s.DamageHits == 0 {
+		return 0
+	}
+	return float64(s.Damage) / float64(s.DamageHits)
+}
+
+// HitStats 타격 플래그 기반 통계
+type HitStats struct {
+	Hits          int
+	DamageHits    int // 피해량이 귀속된 타격 수
+	Damage        uint64
+	Crits         int
+	Breaks        int
+	ElementDamage [len(Elements)]uint64 // Elements와 같은 순서, 원소가 여러 개인 타격은 각각에 더해집니다
+	Unknown       [len(UnknownFlags)]UnknownFlagStats
+}
+
+// CritRate 치명타 비율 (0~1)
+func (s HitStats) CritRate() float64 {
+	if s.Hits == 0 {
+		return 0
+	}
+	return float64(s.Crits) / float64(s.Hits)
+}
+
+// AverageHit 타격당 평균 피해량
+func (s HitStats) AverageHit() float64 {
+	if s.DamageHits == 0 {
+		return 0
+	}
+	return float64(s.Damage) / float64(s.DamageHits)
+}
+
+// ElementShare Elements[i] 원소 피해가 전체 피해에서 차지하는 비율 (0~1)
+func (s HitStats) ElementShare(i int) float64 {
+	if s.Damage == 0 || i < 0 || i >= len(s.ElementDamage) {
+		return 0
+	}
+	return float64(s.ElementDamage[i]) / float64(s.Damage)
+}
+
+// addHit 공격 이벤트 하나의 플래그를 집계합니다
+func (s *HitStats) addHit(attack packet.AttackData) {
+	s.Hits++
+
+	if attack.Flags.Has(packet.FlagCrit) {
+		s.Crits++
+	}
+
+	if attack.Flags.Has(packet.FlagBreak) {
+		s.Breaks++
+	}
+
+	for i, flag := range UnknownFlags {
+		if attack.Flags.Has(flag) {
+			s.Unknown[i].Hits++
+		}
+	}
+}
+
+// addDamage 귀속된 피해량을 원인이 된 공격의 플래그별로 집계합니다
+func (s *HitStats) addDamage(amount uint32, attack packet.AttackData) {
+	s.Damage += uint64(amount)
+	s.DamageHits++
+
+	for i, element := range Elements {
+		if attack.Flags.Has(element) {
+			s.ElementDamage[i] += uint64(amount)
+		}
+	}
+
+	for i, flag := range UnknownFlags {
+		if attack.Flags.Has(flag) {
+			s.Unknown[i].Damage += uint64(amount)
+			s.Unknown[i].DamageHits++
+		}
+	}
+}
+
+// merge other의 통계를 더합니다
+func (s *HitStats) merge(other HitStats) {
+	s.Hits += other.Hits
+	s.DamageHits += other.DamageHits
+	s.Damage += other.Damage
+	s.Crits += other.Crits
+	s.Breaks += other.Breaks
+
+	for i := range s.ElementDamage {
+		s.ElementDamage[i] += other.ElementDamage[i]
+	}
+
+	for i := range s.Unknown {
+		s.Unknown[i].Hits += other.Unknown[i].Hits
+		s.Unknown[i].DamageHits += other.Unknown[i].DamageHits
+		s.Unknown[i].Damage += other.Unknown[i].Damage
+	}
+}
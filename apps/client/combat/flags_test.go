@@ -0,0 +1,108 @@
+package combat
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"mogi-suction/client/packet"
+)
+
+func flagged(flags ...packet.DamageFlag) packet.AttackData {
+	var data packet.AttackData
+	for _, flag := range flags {
+		data.Flags = data.Flags.With(flag)
+	}
+	return data
+}
+
+func elementIndex(t *testing.T, flag packet.DamageFlag) int {
+	t.Helper()
+
+	i := slices.Index(Elements[:], flag)
+	if i < 0 {
+		t.Fatalf("%s is not an element", flag)
+	}
+	return i
+}
+
+func TestHitStatsCritRate(t *testing.T) {
+	var stats HitStats
+	if stats.CritRate() != 0 || stats.AverageHit() != 0 {
+		t.Fatal("empty stats have a non-zero rate")
+	}
+
+	stats.addHit(flagged(packet.FlagCrit))
+	stats.addHit(flagged(packet.FlagCrit, packet.FlagBreak))
+	stats.addHit(flagged())
+	stats.addHit(flagged(packet.FlagBreak))
+
+	if stats.Hits != 4 || stats.Crits != 2 || stats.Breaks != 2 || stats.CritRate() != 0.5 {
+		t.Fatalf("stats = %+v, crit rate %v, want 2 of 4 crits and 2 breaks", stats, stats.CritRate())
+	}
+}
+
+func TestHitStatsElementShare(t *testing.T) {
+	var stats HitStats
+	fire, ice, holy := elementIndex(t, packet.FlagFire), elementIndex(t, packet.FlagIce), elementIndex(t, packet.FlagHoly)
+
+	// 원소가 여러 개인 타격은 각 원소에 모두 더해지므로 비율의 합이 1을 넘을 수 있습니다
+	stats.addDamage(100, flagged(packet.FlagFire, packet.FlagIce))
+	stats.addDamage(300, flagged(packet.FlagFire))
+	stats.addDamage(600, flagged())
+
+	want := map[int]float64{fire: 0.4, ice: 0.1, holy: 0}
+	for i, share := range want {
+		if got := stats.ElementShare(i); math.Abs(got-share) > 1e-9 {
+			t.Errorf("ElementShare(%s) = %v, want %v", Elements[i], got, share)
+		}
+	}
+	if stats.AverageHit() != 1000.0/3 {
+		t.Errorf("AverageHit = %v", stats.AverageHit())
+	}
+
+	for _, i := range []int{-1, len(Elements)} {
+		if got := stats.ElementShare(i); got != 0 {
+			t.Errorf("ElementShare(%d) = %v, want 0", i, got)
+		}
+	}
+}
+
+func TestHitStatsUnknownFlags(t *testing.T) {
+	var stats HitStats
+	what1 := slices.Index(UnknownFlags[:], packet.FlagWhat1)
+	what05 := slices.Index(UnknownFlags[:], packet.FlagWhat05)
+	what06 := slices.Index(UnknownFlags[:], packet.FlagWhat06)
+
+	both := flagged(packet.FlagWhat1, packet.FlagWhat05)
+	stats.addHit(both)
+	stats.addDamage(200, both)
+	stats.addHit(flagged(packet.FlagWhat1))
+	stats.addDamage(100, flagged(packet.FlagWhat1))
+	// 피해가 귀속되지 않은 타격은 Hits에만 셉니다
+	stats.addHit(flagged(packet.FlagWhat1))
+
+	tests := []struct {
+		index       int
+		want        UnknownFlagStats
+		wantAverage float64
+	}{
+		{what1, UnknownFlagStats{Hits: 3, DamageHits: 2, Damage: 300}, 150},
+		{what05, UnknownFlagStats{Hits: 1, DamageHits: 1, Damage: 200}, 200},
+		{what06, UnknownFlagStats{}, 0},
+	}
+	for _, tt := range tests {
+		got := stats.Unknown[tt.index]
+		if got != tt.want || got.AverageHit() != tt.wantAverage {
+			t.Errorf("%s = %+v (average %v), want %+v (average %v)", UnknownFlags[tt.index], got, got.AverageHit(), tt.want, tt.wantAverage)
+		}
+	}
+
+	// 합치면 모든 카운트가 더해집니다
+	var total HitStats
+	total.merge(stats)
+	total.merge(stats)
+	if total.Hits != 6 || total.Unknown[what1].Damage != 600 || total.Unknown[what05].DamageHits != 2 {
+		t.Errorf("merged stats = %+v", total)
+	}
+}
@@ -0,0 +1,217 @@
+package combat
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// DPS 계산 시 나눌 최소 시간 (전투 시작 직후 값이 튀는 것을 방지)
+const minDPSDuration = time.Second
+
+// PlayerDPS 플레이어 한 명의 피해량 스냅샷
+type PlayerDPS struct {
+	UserID       uint32
+	Total        uint64
+	WindowDPS    []float64 // MeterSnapshot.Windows와 같은 순서
+	EncounterDPS float64
+	FirstHit     time.Time
+	LastHit      time.Time
+}
+
+// MeterSnapshot 특정 캡처 시각 기준의 미터 상태
+type MeterSnapshot struct {
+	Now     time.Time
+	Start   time.Time
+	Windows []time.Duration
+	Total   uint64
+	Players []PlayerDPS // 총 피해량 내림차순
+}
+
+type hit struct {
+	timestamp time.Time
+	amount    uint32
+}
+
+type playerMeter struct {
+	total    uint64
+	firstHit time.Time
+	lastHit  time.Time
+	hits     []hit // 가장 긴 윈도우 안의 피해 기록 (시각 순)
+
+	// 윈도우별 누적 합계. hits[windowStart[i]:]가 at 기준 윈도우 i 안의 피해이고 그 합이 windowSum[i]입니다.
+	at          time.Time
+	windowStart []int
+	windowSum   []uint64
+}
+
+// Meter 플레이어별 총 피해량과 슬라이딩 윈도우 DPS를 계산합니다.
+// 시각은 캡처 시각을 사용하며, 고루틴 안전하지 않습니다.
+type Meter struct {
+	windows   []time.Duration
+	maxWindow time.Duration
+	start     time.Time
+	total     uint64
+	players   map[uint32]*playerMeter
+}
+
+func NewMeter(windows []time.Duration) *Meter {
+	m := &Meter{
+		windows: append([]time.Duration(nil), windows...),
+		players: make(map[uint32]*playerMeter),
+	}
+
+	for _, window := range windows {
+		m.maxWindow = max(m.maxWindow, window)
+	}
+
+	return m
+}
+
+// Add 귀속된 피해를 공격자에게 더합니다. 귀속되지 않은 피해는 무시합니다.
+func (m *Meter) Add(damage Damage) {
+	if damage.AttackerID == 0 {
+		return
+	}
+
+	if m.start.IsZero() || damage.Timestamp.Before(m.start) {
+		m.start = damage.Timestamp
+	}
+
+	player, ok := m.players[damage.AttackerID]
+	if !ok {
+		player = &playerMeter{
+			firstHit:    damage.Timestamp,
+			windowStart: make([]int, len(m.windows)),
+			windowSum:   make([]uint64, len(m.windows)),
+		}
+		m.players[damage.AttackerID] = player
+	}
+
+	player.total += uint64(damage.Amount)
+	m.total += uint64(damage.Amount)
+	if damage.Timestamp.After(player.lastHit) {
+		player.lastHit = damage.Timestamp
+	}
+	if damage.Timestamp.Before(player.firstHit) {
+		player.firstHit = damage.Timestamp
+	}
+
+	player.advance(damage.Timestamp, m.windows)
+	player.insert(hit{timestamp: damage.Timestamp, amount: damage.Amount}, m.windows)
+}
+
+// Total 미터에 기록된 총 피해량을 반환합니다
+func (m *Meter) Total() uint64 {
+	return m.total
+}
+
+// Snapshot now 시각 기준으로 플레이어별 DPS를 계산합니다
+func (m *Meter) Snapshot(now time.Time) MeterSnapshot {
+	snapshot := MeterSnapshot{
+		Now:     now,
+		Start:   m.start,
+		Windows: append([]time.Duration(nil), m.windows...),
+		Total:   m.total,
+		Players: make([]PlayerDPS, 0, len(m.players)),
+	}
+
+	elapsed := max(now.Sub(m.start), minDPSDuration)
+
+	for userID, player := range m.players {
+		stats := PlayerDPS{
+			UserID:       userID,
+			Total:        player.total,
+			WindowDPS:    make([]float64, len(m.windows)),
+			EncounterDPS: float64(player.total) / elapsed.Seconds(),
+			FirstHit:     player.firstHit,
+			LastHit:      player.lastHit,
+		}
+
+		player.advance(now, m.windows)
+
+		for i, window := range m.windows {
+			stats.WindowDPS[i] = float64(player.windowTotal(i, now, window)) / min(window, elapsed).Seconds()
+		}
+
+		snapshot.Players = append(snapshot.Players, stats)
+	}
+
+	sort.Slice(snapshot.Players, func(i, j int) bool {
+		if snapshot.Players[i].Total != snapshot.Players[j].Total {
+			return snapshot.Players[i].Total > snapshot.Players[j].Total
+		}
+		return snapshot.Players[i].UserID < snapshot.Players[j].UserID
+	})
+
+	return snapshot
+}
+
+// advance 윈도우 기준 시각을 now로 옮기며 윈도우를 벗어난 피해를 합계에서 뺍니다.
+// 시각이 앞으로만 움직이므로 피해 기록 하나는 윈도우마다 한 번씩만 빠집니다.
+func (p *playerMeter) advance(now time.Time, windows []time.Duration) {
+	if !now.After(p.at) {
+		return
+	}
+	p.at = now
+
+	keep := len(p.hits)
+	for i, window := range windows {
+		cutoff := now.Add(-window)
+		for p.windowStart[i] < len(p.hits) && !p.hits[p.windowStart[i]].timestamp.After(cutoff) {
+			p.windowSum[i] -= uint64(p.hits[p.windowStart[i]].amount)
+			p.windowStart[i]++
+		}
+		keep = min(keep, p.windowStart[i])
+	}
+
+	// 모든 윈도우를 벗어난 기록은 버립니다. 앞을 잘라낸 슬라이스는 다음 재할당 때 남은 기록만 옮겨집니다.
+	if keep > 0 {
+		p.hits = p.hits[keep:]
+		for i := range p.windowStart {
+			p.windowStart[i] -= keep
+		}
+	}
+}
+
+// insert 피해 기록을 시각 순서를 지키며 추가하고, 현재 윈도우 안이면 합계에 더합니다
+func (p *playerMeter) insert(h hit, windows []time.Duration) {
+	// 여러 스트림의 이벤트가 섞여 약간 늦게 도착한 피해도 제자리에 넣습니다
+	pos := len(p.hits)
+	for pos > 0 && p.hits[pos-1].timestamp.After(h.timestamp) {
+		pos--
+	}
+	p.hits = slices.Insert(p.hits, pos, h)
+
+	for i, window := range windows {
+		if h.timestamp.After(p.at.Add(-window)) {
+			p.windowSum[i] += uint64(h.amount)
+		} else {
+			// 윈도우 밖의 기록은 windowStart 앞에 들어갑니다
+			p.windowStart[i]++
+		}
+	}
+
+	if len(windows) == 0 {
+		p.hits = p.hits[:0]
+	}
+}
+
+// windowTotal now 기준 윈도우 i 안의 피해 합계를 반환합니다.
+// now가 마지막으로 옮긴 기준 시각보다 이전이면 남아 있는 기록을 직접 합산합니다.
+func (p *playerMeter) windowTotal(i int, now time.Time, window time.Duration) uint64 {
+	if !now.Before(p.at) {
+		return p.windowSum[i]
+	}
+
+	cutoff := now.Add(-window)
+
+	var sum uint64
+	for _, h := range p.hits {
+		if h.timestamp.After(cutoff) && !h.timestamp.After(now) {
+			sum += uint64(h.amount)
+		}
+	}
+
+	return sum
+}
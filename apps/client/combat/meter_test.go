@@ -0,0 +1,168 @@
+package combat
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func damageBy(attackerID uint32, ms int, amount uint32) Damage {
+	return Damage{Timestamp: at(ms), AttackerID: attackerID, TargetID: 100, Amount: amount}
+}
+
+func assertDPS(t *testing.T, name string, got, want float64) {
+	t.Helper()
+
+	if math.Abs(got-want) > 1e-9 {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestMeterWindows(t *testing.T) {
+	meter := NewMeter([]time.Duration{5 * time.Second, 30 * time.Second})
+
+	meter.Add(damageBy(1, 0, 1000))
+	meter.Add(damageBy(2, 1000, 300))
+	meter.Add(damageBy(1, 10000, 500))
+	meter.Add(damageBy(1, 20000, 200))
+	meter.Add(Damage{Timestamp: at(20000), TargetID: 100, Amount: 999, Unattributed: true})
+
+	snapshot := meter.Snapshot(at(22000))
+
+	if snapshot.Total != 2000 || meter.Total() != 2000 {
+		t.Fatalf("total = %d/%d, want 2000 (unattributed damage ignored)", snapshot.Total, meter.Total())
+	}
+	if !snapshot.Start.Equal(at(0)) || len(snapshot.Players) != 2 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+
+	// 총 피해량 내림차순
+	p1, p2 := snapshot.Players[0], snapshot.Players[1]
+	if p1.UserID != 1 || p2.UserID != 2 {
+		t.Fatalf("players ordered %d, %d, want 1, 2", p1.UserID, p2.UserID)
+	}
+
+	// 5초 창: 17s 이후의 피해만, 30초 창: 경과 시간(22s)으로 나눔
+	assertDPS(t, "player 1 5s DPS", p1.WindowDPS[0], 200.0/5)
+	assertDPS(t, "player 1 30s DPS", p1.WindowDPS[1], 1700.0/22)
+	assertDPS(t, "player 1 encounter DPS", p1.EncounterDPS, 1700.0/22)
+	assertDPS(t, "player 2 5s DPS", p2.WindowDPS[0], 0)
+	assertDPS(t, "player 2 30s DPS", p2.WindowDPS[1], 300.0/22)
+
+	if !p1.FirstHit.Equal(at(0)) || !p1.LastHit.Equal(at(20000)) {
+		t.Fatalf("player 1 hits %v..%v", p1.FirstHit, p1.LastHit)
+	}
+
+	// 5초 창의 끝(정확히 5초 전)은 포함하지 않습니다
+	later := meter.Snapshot(at(25000))
+	assertDPS(t, "player 1 5s DPS at window edge", later.Players[0].WindowDPS[0], 0)
+	assertDPS(t, "player 1 30s DPS after 25s", later.Players[0].WindowDPS[1], 1700.0/25)
+}
+
+func TestMeterShortEncounterUsesMinimumDuration(t *testing.T) {
+	meter := NewMeter([]time.Duration{5 * time.Second})
+	meter.Add(damageBy(1, 0, 100))
+
+	// 첫 타격 직후에는 minDPSDuration으로 나눠 값이 튀지 않게 합니다
+	snapshot := meter.Snapshot(at(100))
+	assertDPS(t, "window DPS", snapshot.Players[0].WindowDPS[0], 100)
+	assertDPS(t, "encounter DPS", snapshot.Players[0].EncounterDPS, 100)
+}
+
+func TestMeterLateHitIsCounted(t *testing.T) {
+	meter := NewMeter([]time.Duration{5 * time.Second})
+
+	meter.Add(damageBy(1, 10000, 100))
+	meter.Add(damageBy(1, 9000, 50))   // 창 안에 늦게 도착
+	meter.Add(damageBy(1, 1000, 1000)) // 창 밖에 늦게 도착
+
+	snapshot := meter.Snapshot(at(12000))
+	assertDPS(t, "window DPS", snapshot.Players[0].WindowDPS[0], 150.0/5)
+	if snapshot.Total != 1150 || !snapshot.Start.Equal(at(1000)) || !snapshot.Players[0].FirstHit.Equal(at(1000)) {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+}
+
+func TestMeterSnapshotBeforeLatestHit(t *testing.T) {
+	meter := NewMeter([]time.Duration{5 * time.Second})
+
+	meter.Add(damageBy(1, 4000, 100))
+	meter.Add(damageBy(1, 6000, 200))
+	meter.Add(damageBy(1, 8000, 400))
+
+	// 마지막 타격 이전 시각의 스냅샷은 그 시각 이후의 피해를 빼고 계산합니다
+	// (가장 긴 창을 벗어나 버려진 기록은 다시 셀 수 없습니다)
+	assertDPS(t, "window DPS at 7s", meter.Snapshot(at(7000)).Players[0].WindowDPS[0], 300.0/3)
+	assertDPS(t, "window DPS at 8s", meter.Snapshot(at(8000)).Players[0].WindowDPS[0], 700.0/4)
+}
+
+// TestMeterMatchesFullScan 누적 합계가 모든 기록을 직접 합산한 값과 같은지 무작위 순서로 확인합니다
+func TestMeterMatchesFullScan(t *testing.T) {
+	windows := []time.Duration{time.Second, 5 * time.Second, 30 * time.Second}
+	meter := NewMeter(windows)
+	rng := rand.New(rand.NewPCG(11, 12))
+
+	var all []Damage
+	now := 0
+	for step := range 5000 {
+		now += rng.IntN(400)
+
+		// 가끔 조금 늦게 도착한 피해를 섞습니다
+		ts := now
+		if rng.IntN(10) == 0 {
+			ts -= rng.IntN(3000)
+		}
+
+		damage := damageBy(uint32(1+rng.IntN(4)), ts, uint32(1+rng.IntN(1000)))
+		meter.Add(damage)
+		all = append(all, damage)
+
+		if step%50 != 0 {
+			continue
+		}
+
+		snapshot := meter.Snapshot(at(now))
+		elapsed := max(at(now).Sub(snapshot.Start), minDPSDuration)
+
+		for _, player := range snapshot.Players {
+			for i, window := range windows {
+				var sum uint64
+				for _, d := range all {
+					if d.AttackerID == player.UserID && d.Timestamp.After(at(now).Add(-window)) && !d.Timestamp.After(at(now)) {
+						sum += uint64(d.Amount)
+					}
+				}
+
+				assertDPS(t, "window DPS", player.WindowDPS[i], float64(sum)/min(window, elapsed).Seconds())
+			}
+		}
+	}
+
+	// 가장 긴 창을 벗어난 기록은 버려져야 합니다
+	for id, player := range meter.players {
+		if len(player.hits) > len(all) {
+			t.Fatalf("player %d keeps %d hits", id, len(player.hits))
+		}
+		for _, h := range player.hits {
+			if !h.timestamp.After(player.at.Add(-30 * time.Second)) {
+				t.Fatalf("player %d keeps a hit outside every window", id)
+			}
+		}
+	}
+}
+
+func BenchmarkMeterSnapshot(b *testing.B) {
+	meter := NewMeter([]time.Duration{5 * time.Second, 30 * time.Second})
+	for i := range 20000 {
+		meter.Add(damageBy(uint32(1+i%8), i*2, 100))
+	}
+
+	b.ReportAllocs()
+	ms := 40000
+	for b.Loop() {
+		ms++
+		meter.Add(damageBy(1, ms, 100))
+		meter.Snapshot(at(ms))
+	}
+}
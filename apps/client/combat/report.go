@@ -0,0 +1,98 @@
+package combat
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteEncounterReport 전투 보고서를 사람이 읽을 수 있는 형태로 w에 기록합니다
+func WriteEncounterReport(w io.Writer, report EncounterReport) error {
+	var b strings.Builder
+
+	reason := string(report.EndReason)
+	if reason == "" {
+		reason = "in progress"
+	}
+
+	fmt.Fprintf(&b, "encounter #%d: %s, %s (%s), total %d, %d participants\n",
+		report.ID, report.Label(report.MainTarget), report.Duration.Round(time.Millisecond), reason, report.TotalDamage, len(report.Participants))
+	writeTargetHP(&b, report.TargetHP)
+	writeMeter(&b, report.Meter, report.Label)
+	writeSkills(&b, report.Skills, report.Label)
+	writeDoTs(&b, report.DoTs, report.Label)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeTargetHP(b *strings.Builder, hp TargetHP) {
+	if hp.MaxHP == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "  hp %5.1f%% (%d/%d)  rate %10.0f/s", hp.Remaining*100, hp.Current, hp.MaxHP, hp.DamageRate)
+	if hp.TimeToKill > 0 {
+		fmt.Fprintf(b, "  ttk %s", hp.TimeToKill.Round(time.Second))
+	}
+	for _, mark := range hp.Phases {
+		fmt.Fprintf(b, "  %.0f%% @ %s", mark.Threshold*100, mark.Elapsed.Round(100*time.Millisecond))
+	}
+	b.WriteString("\n")
+}
+
+func writeMeter(b *strings.Builder, snapshot MeterSnapshot, label func(uint32) string) {
+	for _, player := range snapshot.Players {
+		share := 0.0
+		if snapshot.Total > 0 {
+			share = float64(player.Total) / float64(snapshot.Total) * 100
+		}
+
+		fmt.Fprintf(b, "  %-24s  total %12d (%5.1f%%)  dps %10.0f", label(player.UserID), player.Total, share, player.EncounterDPS)
+		for i, window := range snapshot.Windows {
+			fmt.Fprintf(b, "  %s %10.0f", window, player.WindowDPS[i])
+		}
+		b.WriteString("\n")
+	}
+}
+
+func writeSkills(b *strings.Builder, players []PlayerSkills, label func(uint32) string) {
+	for _, player := range players {
+		fmt.Fprintf(b, "  skills of %s:\n", label(player.UserID))
+		for _, skill := range player.Skills {
+			fmt.Fprintf(b, "    %-24s  casts %4d  hits %5d  damage %12d  crit %5.1f%%  break %4d  avg %10.0f\n",
+				skill.Name, skill.Casts, skill.Hits, skill.Damage, skill.CritRate()*100, skill.Breaks, skill.AverageHit())
+		}
+		writeHitFlags(b, player.Total)
+	}
+}
+
+func writeDoTs(b *strings.Builder, players []PlayerDoTs, label func(uint32) string) {
+	for _, player := range players {
+		fmt.Fprintf(b, "  dots of %s: ticks %d  damage %d\n", label(player.UserID), player.Ticks, player.Damage)
+		for _, dot := range player.DoTs {
+			fmt.Fprintf(b, "    %-24s  on %-20s  applied %3d  ticks %5d  damage %12d  uptime %5.1f%%\n",
+				dot.Skill, label(dot.TargetID), dot.Instances, dot.Ticks, dot.Damage, dot.Uptime*100)
+		}
+	}
+}
+
+// writeHitFlags 원소별 피해 비율과 미확인 플래그 통계를 기록합니다
+func writeHitFlags(b *strings.Builder, stats HitStats) {
+	fmt.Fprintf(b, "    flags: crit %5.1f%%  break %d", stats.CritRate()*100, stats.Breaks)
+	for i, element := range Elements {
+		if stats.ElementDamage[i] > 0 {
+			fmt.Fprintf(b, "  %s %.1f%%", element, stats.ElementShare(i)*100)
+		}
+	}
+	b.WriteString("\n")
+
+	for i, flag := range UnknownFlags {
+		unknown := stats.Unknown[i]
+		if unknown.Hits == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "    %s: hits %d/%d  avg %.0f (overall %.0f)\n", flag, unknown.Hits, stats.Hits, unknown.AverageHit(), stats.AverageHit())
+	}
+}
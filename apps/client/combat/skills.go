@@ -0,0 +1,176 @@
+package combat
+
+import (
+	"sort"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// UnknownSkill 스킬 사용 정보와 연결되지 않은 공격이 모이는 이름
+const UnknownSkill = "unknown skill"
+
+// 스킬 사용 키를 공격과 연결할 수 있는 최대 시간
+const skillKeyTTL = time.Minute
+
+// SkillStats 플레이어 한 명의 스킬 하나에 대한 통계
+type SkillStats struct {
+	Name  string
+	Casts int
+	HitStats
+}
+
+// PlayerSkills 플레이어 한 명의 스킬별 통계 (피해량 내림차순)와 전체 합계
+type PlayerSkills struct {
+	UserID uint32
+	Total  HitStats
+	Skills []SkillStats
+}
+
+type skillCast struct {
+	timestamp time.Time
+	userID    uint32
+	name      string
+	counted   int // 사용 횟수가 집계된 전투 ID
+}
+
+// skillCastKey 스킬 사용 키는 플레이어마다 따로 매겨질 수 있으므로 사용자와 함께 구분합니다
+type skillCastKey struct {
+	userID uint32
+	key    uint32
+}
+
+// skillResolver 같은 플레이어의 ActionData Key1로 공격이 어떤 스킬에서 나왔는지 찾습니다.
+// 고루틴 안전하지 않습니다.
+type skillResolver struct {
+	casts     map[skillCastKey]*skillCast
+	lastSweep time.Time
+}
+
+func newSkillResolver() *skillResolver {
+	return &skillResolver{
+		casts: make(map[skillCastKey]*skillCast),
+	}
+}
+
+// addCast 스킬 사용을 기록합니다
+func (r *skillResolver) addCast(timestamp time.Time, action packet.ActionData) *skillCast {
+	r.sweep(timestamp)
+
+	name := action.SkillName
+	if name == "" {
+		name = UnknownSkill
+	}
+
+	cast := &skillCast{
+		timestamp: timestamp,
+		userID:    action.UserID,
+		name:      name,
+	}
+	r.casts[skillCastKey{action.UserID, action.Key1}] = cast
+	return cast
+}
+
+// resolve 공격자의 스킬 사용 기록을 공격의 Key1, Key2 순으로 찾습니다.
+// 다른 플레이어가 같은 키로 사용한 스킬과는 연결하지 않습니다.
+func (r *skillResolver) resolve(attack packet.AttackData) (*skillCast, bool) {
+	for _, key := range [...]uint32{attack.Key1, attack.Key2} {
+		if cast, ok := r.casts[skillCastKey{attack.UserID, key}]; ok && key != 0 {
+			return cast, true
+		}
+	}
+
+	return nil, false
+}
+
+// skillName 공격의 스킬 이름을 반환합니다. 찾지 못하면 UnknownSkill을 반환합니다.
+func (r *skillResolver) skillName(attack packet.AttackData) string {
+	if cast, ok := r.resolve(attack); ok {
+		return cast.name
+	}
+
+	return UnknownSkill
+}
+
+func (r *skillResolver) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < skillKeyTTL {
+		return
+	}
+	r.lastSweep = now
+
+	cutoff := now.Add(-skillKeyTTL)
+	for key, cast := range r.casts {
+		if cast.timestamp.Before(cutoff) {
+			delete(r.casts, key)
+		}
+	}
+}
+
+// SkillBreakdown 플레이어별 스킬 통계. 고루틴 안전하지 않습니다.
+type SkillBreakdown struct {
+	players map[uint32]map[string]*SkillStats
+}
+
+func NewSkillBreakdown() *SkillBreakdown {
+	return &SkillBreakdown{
+		players: make(map[uint32]map[string]*SkillStats),
+	}
+}
+
+// AddCast 스킬 사용 횟수를 더합니다
+func (b *SkillBreakdown) AddCast(userID uint32, name string) {
+	b.stats(userID, name).Casts++
+}
+
+// AddHit 공격 이벤트의 타격 횟수와 플래그를 더합니다
+func (b *SkillBreakdown) AddHit(name string, attack packet.AttackData) {
+	b.stats(attack.UserID, name).addHit(attack)
+}
+
+// AddDamage 귀속된 피해량을 더합니다
+func (b *SkillBreakdown) AddDamage(name string, damage Damage) {
+	b.stats(damage.AttackerID, name).addDamage(damage.Amount, damage.Attack)
+}
+
+// Snapshot 플레이어별 스킬 통계의 복사본을 반환합니다
+func (b *SkillBreakdown) Snapshot() []PlayerSkills {
+	players := make([]PlayerSkills, 0, len(b.players))
+	for userID, skills := range b.players {
+		player := PlayerSkills{
+			UserID: userID,
+			Skills: make([]SkillStats, 0, len(skills)),
+		}
+		for _, stats := range skills {
+			player.Skills = append(player.Skills, *stats)
+			player.Total.merge(stats.HitStats)
+		}
+
+		sort.Slice(player.Skills, func(i, j int) bool {
+			if player.Skills[i].Damage != player.Skills[j].Damage {
+				return player.Skills[i].Damage > player.Skills[j].Damage
+			}
+			return player.Skills[i].Name < player.Skills[j].Name
+		})
+
+		players = append(players, player)
+	}
+
+	sort.Slice(players, func(i, j int) bool { return players[i].UserID < players[j].UserID })
+	return players
+}
+
+func (b *SkillBreakdown) stats(userID uint32, name string) *SkillStats {
+	skills, ok := b.players[userID]
+	if !ok {
+		skills = make(map[string]*SkillStats)
+		b.players[userID] = skills
+	}
+
+	stats, ok := skills[name]
+	if !ok {
+		stats = &SkillStats{Name: name}
+		skills[name] = stats
+	}
+
+	return stats
+}
@@ -0,0 +1,103 @@
+package combat
+
+import (
+	"testing"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+func TestSkillResolverJoinsCastsByUserAndKey(t *testing.T) {
+	resolver := newSkillResolver()
+	resolver.addCast(at(0), packet.ActionData{UserID: 1, SkillName: "Slash", Key1: 11})
+	resolver.addCast(at(10), packet.ActionData{UserID: 2, SkillName: "Fireball", Key1: 11})
+	resolver.addCast(at(20), packet.ActionData{UserID: 1, Key1: 12})
+
+	withKey2 := attack(1, 100, 99)
+	withKey2.Key2 = 11
+
+	tests := []struct {
+		name   string
+		attack packet.AttackData
+		want   string
+	}{
+		{"own cast", attack(1, 100, 11), "Slash"},
+		// 다른 플레이어가 같은 키로 사용한 스킬에 덮어쓰이지 않습니다
+		{"same key from another player", attack(2, 100, 11), "Fireball"},
+		{"key nobody cast", attack(1, 100, 13), UnknownSkill},
+		{"key cast only by another player", attack(3, 100, 11), UnknownSkill},
+		{"falls back to Key2", withKey2, "Slash"},
+		{"cast without a name", attack(1, 100, 12), UnknownSkill},
+		{"zero key is never joined", attack(1, 100, 0), UnknownSkill},
+	}
+
+	for _, tt := range tests {
+		if got := resolver.skillName(tt.attack); got != tt.want {
+			t.Errorf("%s: skillName = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 오래된 사용 기록은 연결하지 않습니다
+	resolver.addCast(at(20).Add(skillKeyTTL+time.Millisecond), packet.ActionData{UserID: 9, Key1: 1})
+	if got := resolver.skillName(attack(1, 100, 11)); got != UnknownSkill {
+		t.Errorf("expired cast still joined as %q", got)
+	}
+}
+
+// skillStats 보고서에서 플레이어의 스킬별 통계를 찾습니다
+func skillStats(t *testing.T, report EncounterReport, userID uint32) map[string]SkillStats {
+	t.Helper()
+
+	for _, player := range report.Skills {
+		if player.UserID == userID {
+			stats := make(map[string]SkillStats)
+			for _, skill := range player.Skills {
+				stats[skill.Name] = skill
+			}
+			return stats
+		}
+	}
+
+	t.Fatalf("no skills for player %d in %+v", userID, report.Skills)
+	return nil
+}
+
+func TestEngineSkillBreakdown(t *testing.T) {
+	engine, reports := newTestEngine(DefaultConfig())
+
+	// 전투 시작 전의 사용도 그 전투의 사용 횟수에 포함되고, 여러 번 맞혀도 한 번만 셉니다
+	startFight(engine, 0)
+	engine.Handle(attackEvent(100, attack(1, 100, 11)))
+	engine.Handle(hpEvent(110, 100, 900, 850))
+
+	engine.Handle(castEvent(200, 1, "Slash", 12))
+	engine.Handle(attackEvent(210, attack(1, 100, 12)))
+	engine.Handle(hpEvent(220, 100, 850, 830))
+
+	// 사용 기록이 없는 공격은 알 수 없는 스킬로 모입니다
+	engine.Handle(attackEvent(300, attack(1, 100, 77)))
+	engine.Handle(hpEvent(310, 100, 830, 825))
+
+	// 다른 플레이어가 같은 키로 공격해도 플레이어 1의 스킬로 세지 않습니다
+	engine.Handle(attackEvent(400, attack(2, 100, 11)))
+	engine.Handle(hpEvent(410, 100, 825, 800))
+	engine.Flush()
+
+	if len(*reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(*reports))
+	}
+	report := (*reports)[0]
+
+	stats := skillStats(t, report, 1)
+	if slash := stats["Slash"]; slash.Casts != 2 || slash.Hits != 3 || slash.Damage != 170 {
+		t.Errorf("Slash = %+v, want 2 casts, 3 hits and 170 damage", slash)
+	}
+	if unknown := stats[UnknownSkill]; unknown.Casts != 0 || unknown.Hits != 1 || unknown.Damage != 5 {
+		t.Errorf("unknown skill = %+v, want 1 hit and 5 damage", unknown)
+	}
+
+	other := skillStats(t, report, 2)
+	if _, ok := other["Slash"]; ok || other[UnknownSkill].Damage != 25 {
+		t.Errorf("player 2 skills = %+v, want 25 damage on the unknown skill", other)
+	}
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+// 캡처 모드
+const (
+	modeLive = "live" // 네트워크 인터페이스 라이브 캡처
+	modeFile = "file" // PCAP 파일 재생
+)
+
+// 출력 대상
+const (
+	outputEvents  = "events"  // 파싱된 이벤트 로그
+	outputDamage  = "damage"  // 피해 귀속 로그
+	outputMeter   = "meter"   // 진행 중인 전투의 주기적 DPS 미터
+	outputReports = "reports" // 전투 종료 보고서와 HP 단계 로그
+	outputQUIC    = "quic"    // QUIC 서버 연결
+	outputCatalog = "catalog" // 미확인 타입 카탈로그 파일
+)
+
+var knownOutputs = []string{outputEvents, outputDamage, outputMeter, outputReports, outputQUIC, outputCatalog}
+
+// defaultOutputs 이벤트 로그는 양이 많아 명시적으로 켤 때만 출력합니다
+var defaultOutputs = []string{outputDamage, outputMeter, outputReports, outputQUIC, outputCatalog}
+
+// quicALPN 서버와 맞춰야 하는 QUIC 애플리케이션 프로토콜
+const quicALPN = "mogi-suction-quic"
+
+// Config 클라이언트 설정. 설정 파일(JSON)을 먼저 읽고 명령행 플래그로 덮어씁니다.
+type Config struct {
+	Mode       string   `json:"mode"`       // live 또는 file
+	Interfaces []string `json:"interfaces"` // 동시에 캡처할 인터페이스들 (이름, IP 주소 또는 auto)
+	Probe      Duration `json:"probe"`      // auto 선택 시 인터페이스마다 게임 트래픽을 기다리는 시간
+	PcapFiles  []string `json:"pcap_files"` // file 모드에서 순서대로 읽을 PCAP 파일. 비어 있으면 기본 샘플
+	Speed      string   `json:"speed"`      // file 모드 재생 배속 (max, 0.5x, 1x, 10x 등)
+	Filter     string   `json:"filter"`     // BPF 필터. 비어 있으면 프로토콜 프로필의 포트로 만듭니다
+	Port       int      `json:"port"`       // 게임 서버 포트. 0이면 프로토콜 프로필의 포트
+
+	ServerAddr     string    `json:"server_addr"`
+	ConnectTimeout Duration  `json:"connect_timeout"`
+	TLS            TLSConfig `json:"tls"`
+
+	Outputs         []string `json:"outputs"`
+	MeterLogPeriod  Duration `json:"meter_log_period"`
+	CatalogDumpPath string   `json:"catalog_dump_path"`
+
+	ProtocolProfilePath string `json:"protocol_profile_path"` // 파일이 없으면 내장 기본 프로필
+	ProtocolProfile     string `json:"protocol_profile"`      // 비어 있으면 파일의 첫 번째 프로필
+
+	LocalPlayerID uint32 `json:"local_player_id"` // 0이면 송신 세그먼트로 추론합니다
+}
+
+// TLSConfig QUIC 서버 연결 TLS 설정
+type TLSConfig struct {
+	Insecure   bool   `json:"insecure"`    // 서버 인증서를 검증하지 않습니다 (개발용 자가서명 인증서). CAFile과 함께 쓸 수 없습니다
+	CAFile     string `json:"ca_file"`     // 서버 인증서를 검증할 CA 인증서
+	ServerName string `json:"server_name"` // 비어 있으면 서버 주소의 호스트
+	CertFile   string `json:"cert_file"`   // 클라이언트 인증서 (상호 TLS)
+	KeyFile    string `json:"key_file"`
+}
+
+// Duration JSON과 플래그에서 "10s" 같은 문자열로 표현하는 시간
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Set flag.Value 구현
+func (d *Duration) Set(value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+
+	return d.Set(s)
+}
+
+// DefaultConfig 기본 클라이언트 설정을 반환합니다
+func DefaultConfig() Config {
+	return Config{
+		Mode:                modeFile,
+		Interfaces:          []string{packet.InterfaceAuto},
+		Probe:               Duration(3 * time.Second),
+		Speed:               "max",
+		ServerAddr:          "localhost:8443",
+		ConnectTimeout:      Duration(10 * time.Second),
+		Outputs:             slices.Clone(defaultOutputs),
+		MeterLogPeriod:      Duration(5 * time.Second),
+		CatalogDumpPath:     "unknown_types.json",
+		ProtocolProfilePath: "protocol.json",
+	}
+}
+
+// LoadConfig 명령행 인자와 -config로 지정한 설정 파일로 설정을 만들고 검증합니다.
+// 플래그는 설정 파일의 값보다 우선합니다.
+func LoadConfig(args []string, output io.Writer) (Config, error) {
+	// 설정 파일 경로를 먼저 찾기 위해 한 번 파싱합니다
+	probe := DefaultConfig()
+	var configPath string
+	if err := newFlagSet(&probe, &configPath, io.Discard).Parse(args); err != nil {
+		// 도움말과 오류 메시지는 아래의 두 번째 파싱에서 출력합니다
+		configPath = ""
+	}
+
+	config := DefaultConfig()
+	if configPath != "" {
+		if err := readConfigFile(configPath, &config); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := newFlagSet(&config, &configPath, output).Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+func newFlagSet(config *Config, configPath *string, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	fs.StringVar(configPath, "config", "", "JSON config file; flags override its values")
+	fs.StringVar(&config.Mode, "mode", config.Mode, "capture mode: live or file")
+	fs.Func("interface", "network interface for live capture: name, IP address or auto; repeat or separate with commas to capture several at once (default auto)", func(value string) error {
+		// 설정 파일의 목록은 첫 번째 -interface 플래그가 대체합니다
+		if !flagSeen(fs, "interface") {
+			config.Interfaces = nil
+		}
+		config.Interfaces = append(config.Interfaces, splitList(value)...)
+		return nil
+	})
+	fs.Var(&config.Probe, "probe", "how long auto interface selection watches each interface for game traffic")
+	fs.Func("pcap", "pcap file to read in file mode; repeat or separate with commas for several files", func(value string) error {
+		if value == "" {
+			return errors.New("empty pcap path")
+		}
+		// 설정 파일의 목록은 첫 번째 -pcap 플래그가 대체합니다
+		if !flagSeen(fs, "pcap") {
+			config.PcapFiles = nil
+		}
+		config.PcapFiles = append(config.PcapFiles, splitList(value)...)
+		return nil
+	})
+	fs.StringVar(&config.Speed, "speed", config.Speed, "replay speed in file mode: max, or a multiplier such as 0.5x, 1x or 10x")
+	fs.StringVar(&config.Filter, "filter", config.Filter, "BPF filter (default: tcp port of the protocol profile)")
+	fs.IntVar(&config.Port, "port", config.Port, "game server port (default: protocol profile port)")
+
+	fs.StringVar(&config.ServerAddr, "server", config.ServerAddr, "QUIC server address (host:port)")
+	fs.Var(&config.ConnectTimeout, "connect-timeout", "QUIC connect timeout")
+	fs.BoolVar(&config.TLS.Insecure, "tls-insecure", config.TLS.Insecure, "skip QUIC server certificate verification")
+	fs.StringVar(&config.TLS.CAFile, "tls-ca", config.TLS.CAFile, "CA certificate for verifying the QUIC server")
+	fs.StringVar(&config.TLS.ServerName, "tls-server-name", config.TLS.ServerName, "expected QUIC server name (default: server host)")
+	fs.StringVar(&config.TLS.CertFile, "tls-cert", config.TLS.CertFile, "client certificate for mutual TLS")
+	fs.StringVar(&config.TLS.KeyFile, "tls-key", config.TLS.KeyFile, "client private key for mutual TLS")
+
+	fs.Func("output", fmt.Sprintf("comma separated outputs: %s (default %s)", strings.Join(knownOutputs, ","), strings.Join(defaultOutputs, ",")), func(value string) error {
+		config.Outputs = splitList(value)
+		return nil
+	})
+	fs.Var(&config.MeterLogPeriod, "meter-period", "DPS meter log period")
+	fs.StringVar(&config.CatalogDumpPath, "catalog", config.CatalogDumpPath, "unknown type catalog output file")
+
+	fs.StringVar(&config.ProtocolProfilePath, "protocol", config.ProtocolProfilePath, "protocol profile file (built-in default if missing)")
+	fs.StringVar(&config.ProtocolProfile, "profile", config.ProtocolProfile, "protocol profile name or game version (default: first in file); other than \"default\" requires the protocol file")
+
+	fs.Func("local-player", "local player ID (default: inferred from outbound traffic)", func(value string) error {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid player ID %q", value)
+		}
+		config.LocalPlayerID = uint32(id)
+		return nil
+	})
+
+	return fs
+}
+
+// flagSeen 이미 name 플래그를 처리했는지 확인합니다
+func flagSeen(fs *flag.FlagSet, name string) bool {
+	seen := false
+	fs.Visit(func(f *flag.Flag) {
+		seen = seen || f.Name == name
+	})
+	return seen
+}
+
+// Validate 설정 값을 검사하고, 문제가 있으면 모든 문제를 모아 반환합니다
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch c.Mode {
+	case modeLive:
+		if len(c.PcapFiles) > 0 {
+			invalid("pcap files are only used in %s mode", modeFile)
+		}
+		if len(c.Interfaces) == 0 {
+			invalid("at least one interface is required in %s mode", modeLive)
+		}
+		if slices.Contains(c.Interfaces, packet.InterfaceAuto) && c.Probe <= 0 {
+			invalid("interface probe duration must be positive")
+		}
+	case modeFile:
+		if len(c.Interfaces) > 0 && !slices.Equal(c.Interfaces, []string{packet.InterfaceAuto}) {
+			invalid("interface is only used in %s mode", modeLive)
+		}
+		for _, path := range c.PcapFiles {
+			if err := checkFile(path); err != nil {
+				invalid("pcap file: %v", err)
+			}
+		}
+		if _, err := packet.ParseReplaySpeed(c.Speed); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		invalid("mode must be %q or %q, got %q", modeLive, modeFile, c.Mode)
+	}
+
+	if c.Port < 0 || c.Port > 65535 {
+		invalid("port must be between 1 and 65535, or 0 for the protocol profile port, got %d", c.Port)
+	}
+	if c.Port != 0 && c.Filter != "" {
+		invalid("port and filter cannot be used together; include the port in the filter")
+	}
+
+	// 파일이 없으면 내장 기본 프로필만 사용할 수 있으므로 다른 프로필을 고른 경우 조용히 기본값으로 넘어가지 않습니다
+	if c.ProtocolProfile != "" && c.ProtocolProfile != packet.DefaultProfile().Name {
+		if err := checkFile(c.ProtocolProfilePath); err != nil {
+			invalid("protocol profile %q requires a protocol file: %v", c.ProtocolProfile, err)
+		}
+	}
+
+	for _, output := range c.Outputs {
+		if !slices.Contains(knownOutputs, output) {
+			invalid("unknown output %q (known: %s)", output, strings.Join(knownOutputs, ", "))
+		}
+	}
+
+	if c.MeterLogPeriod <= 0 && c.hasOutput(outputMeter) {
+		invalid("meter log period must be positive")
+	}
+	if c.CatalogDumpPath == "" && c.hasOutput(outputCatalog) {
+		invalid("catalog output requires a catalog file path")
+	}
+
+	if c.hasOutput(outputQUIC) {
+		if _, _, err := net.SplitHostPort(c.ServerAddr); err != nil {
+			invalid("server address %q: %v", c.ServerAddr, err)
+		}
+		if c.ConnectTimeout <= 0 {
+			invalid("connect timeout must be positive")
+		}
+		errs = append(errs, c.TLS.validate()...)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t TLSConfig) validate() []error {
+	var errs []error
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, errors.New("tls cert and key must be set together"))
+	}
+	if t.Insecure && t.CAFile != "" {
+		errs = append(errs, errors.New("tls insecure and CA file cannot be used together"))
+	}
+
+	for _, path := range []string{t.CAFile, t.CertFile, t.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if err := checkFile(path); err != nil {
+			errs = append(errs, fmt.Errorf("tls: %w", err))
+		}
+	}
+
+	return errs
+}
+
+// Build QUIC 연결에 사용할 tls.Config를 만듭니다
+func (t TLSConfig) Build() (*tls.Config, error) {
+	conf := &tls.Config{
+		InsecureSkipVerify: t.Insecure,
+		ServerName:         t.ServerName,
+		NextProtos:         []string{quicALPN},
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", t.CAFile)
+		}
+		conf.RootCAs = pool
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return conf, nil
+}
+
+func (c Config) hasOutput(output string) bool {
+	return slices.Contains(c.Outputs, output)
+}
+
+func readConfigFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func checkFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
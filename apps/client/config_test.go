@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testFile dir에 빈 파일을 만들고 경로를 반환합니다
+func testFile(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func loadTestConfig(t *testing.T, args ...string) (Config, error) {
+	t.Helper()
+
+	pcap := testFile(t, t.TempDir(), "capture.pcap")
+	return LoadConfig(append([]string{"-pcap", pcap}, args...), io.Discard)
+}
+
+func TestConfigProfileRequiresProtocolFile(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.json")
+	existing := testFile(t, dir, "protocol.json")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no profile without file", []string{"-protocol", missing}, ""},
+		{"builtin profile without file", []string{"-protocol", missing, "-profile", "default"}, ""},
+		{"named profile without file", []string{"-protocol", missing, "-profile", "kr-1.2"}, `protocol profile "kr-1.2" requires a protocol file`},
+		{"named profile with file", []string{"-protocol", existing, "-profile", "kr-1.2"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestConfig(t, tt.args...)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("LoadConfig: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("LoadConfig error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	config, err := loadTestConfig(t)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	// 서버 인증서는 기본으로 검증하고, 이벤트 로그는 명시적으로 켤 때만 출력합니다
+	if config.TLS.Insecure {
+		t.Error("TLS insecure mode is on by default")
+	}
+	if config.hasOutput(outputEvents) {
+		t.Error("events output is on by default")
+	}
+	for _, output := range defaultOutputs {
+		if !config.hasOutput(output) {
+			t.Errorf("default output %q is off", output)
+		}
+	}
+
+	config, err = loadTestConfig(t, "-output", "events,damage")
+	if err != nil || !config.hasOutput(outputEvents) || config.hasOutput(outputMeter) {
+		t.Fatalf("-output events,damage = %v, %v", config.Outputs, err)
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	ca := testFile(t, t.TempDir(), "ca.pem")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"CA file alone", []string{"-tls-ca", ca}, ""},
+		{"insecure alone", []string{"-tls-insecure"}, ""},
+		{"insecure with CA file", []string{"-tls-insecure", "-tls-ca", ca}, "tls insecure and CA file cannot be used together"},
+		{"profile port", []string{"-port", "0"}, ""},
+		{"explicit port", []string{"-port", "65535"}, ""},
+		{"negative port", []string{"-port", "-1"}, "port must be between 1 and 65535, or 0 for the protocol profile port"},
+		{"port too large", []string{"-port", "65536"}, "port must be between 1 and 65535, or 0 for the protocol profile port"},
+		{"unknown output", []string{"-output", "damage,chat"}, `unknown output "chat"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestConfig(t, tt.args...)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("LoadConfig: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("LoadConfig error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ module mogi-suction/client
 go 1.24
 
 require (
+	github.com/andybalholm/brotli v1.2.0
 	github.com/google/gopacket v1.1.19
+	github.com/quic-go/quic-go v0.54.0
 	github.com/shirou/gopsutil/v3 v3.24.5
 )
 
@@ -11,7 +13,6 @@ require (
 	github.com/go-ole/go-ole v1.3.0 // indirect
 	github.com/lufia/plan9stats v0.0.0-20250317134145-8bc96cf8fc35 // indirect
 	github.com/power-devops/perfstat v0.0.0-20240221224432-82ca36839d55 // indirect
-	github.com/quic-go/quic-go v0.54.0 // indirect
 	github.com/tklauser/go-sysconf v0.3.15 // indirect
 	github.com/tklauser/numcpus v0.10.0 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
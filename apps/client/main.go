@@ -2,21 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"mogi-suction/client/combat"
 	"mogi-suction/client/packet"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
 
-const (
-	quicServerAddr = "localhost:8443"
-	connectTimeout = 10 * time.Second
-)
+const eventBufferSize = 1024
 
 func main() {
-	log.Println("Starting packet capture with TCP reassembly and QUIC client...")
+	config, err := LoadConfig(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n\nRun with -h for usage.\n", err)
+		os.Exit(2)
+	}
+
+	log.Printf("Starting packet capture with TCP reassembly (%s mode)...", config.Mode)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -26,13 +37,110 @@ func main() {
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	defer signal.Stop(sigCh)
 
-	// QUIC 클라이언트 연결
-	quicClient := NewQUICClient(quicServerAddr)
-	defer quicClient.Close()
+	if config.hasOutput(outputQUIC) {
+		if err := connectQUIC(ctx, cancel, config); err != nil {
+			log.Fatal("Failed to configure QUIC client: ", err)
+		}
+	}
+
+	if err := loadProtocolProfile(config); err != nil {
+		log.Fatal("Failed to load protocol profile: ", err)
+	}
+
+	if err := initPacketSniffer(ctx, config); err != nil {
+		log.Fatal("Failed to initialize packet sniffer: ", err)
+	}
+	defer packet.ClosePacketSniffer()
+
+	// 파싱된 이벤트 로깅 (스니퍼 종료 시 채널이 닫히면 고루틴도 종료됩니다)
+	if config.hasOutput(outputEvents) {
+		events := packet.Subscribe(eventBufferSize)
+		defer events.Close()
+
+		go func() {
+			for event := range events.C {
+				log.Printf("%s [%s]: %+v", event.Name, event.Direction, event.Payload)
+			}
+		}()
+	}
+
+	// 전투 분석 엔진 (피해 귀속). 분석 결과가 빠지지 않도록 스니퍼를 기다리게 하는 구독을 사용합니다.
+	engineConfig := combat.DefaultConfig()
+	engineConfig.WallClock = config.Mode == modeLive
+	engine := combat.NewEngine(engineConfig)
+	if config.hasOutput(outputDamage) {
+		engine.OnDamage(logDamage)
+	}
+
+	if config.hasOutput(outputReports) {
+		engine.OnPhase(func(targetID uint32, mark combat.PhaseMark) {
+			log.Printf("Target %d below %.0f%% HP after %s", targetID, mark.Threshold*100, mark.Elapsed.Round(100*time.Millisecond))
+		})
+		engine.OnEncounterEnd(logEncounterReport)
+	}
+
+	engineEvents := packet.SubscribeBlocking(eventBufferSize)
+	defer engineEvents.Close()
+
+	engineDone := make(chan struct{})
+	go func() {
+		defer close(engineDone)
+		engine.Run(engineEvents.C)
+	}()
+
+	if config.hasOutput(outputMeter) {
+		go logMeter(ctx, engine, time.Duration(config.MeterLogPeriod))
+	}
+
+	packet.StartPacketSniffer()
+
+	// PCAP 재생 제어 (표준 입력)
+	if config.Mode == modeFile {
+		log.Print(replayControlHelp)
+		go readReplayControls(os.Stdin)
+	}
+
+	// 미확인 타입 카탈로그 덤프 요청 (SIGUSR1)
+	dumpCh := make(chan os.Signal, 1)
+	if config.hasOutput(outputCatalog) {
+		notifyCatalogDump(dumpCh)
+		defer signal.Stop(dumpCh)
+	}
+
+	// 시그널 대기 및 종료
+	for waiting := true; waiting; {
+		select {
+		case <-dumpCh:
+			dumpCatalog(config.CatalogDumpPath)
+		case <-sigCh:
+			waiting = false
+		}
+	}
+
+	log.Println("🛑 Shutting down...")
+	cancel()
+	packet.StopPacketSniffer()
+	<-engineDone
+
+	if config.hasOutput(outputCatalog) {
+		dumpCatalog(config.CatalogDumpPath)
+	}
+}
+
+// connectQUIC QUIC 서버에 백그라운드로 연결합니다. 연결에 실패하면 cancel로 클라이언트를 종료합니다.
+func connectQUIC(ctx context.Context, cancel context.CancelFunc, config Config) error {
+	tlsConf, err := config.TLS.Build()
+	if err != nil {
+		return err
+	}
+
+	quicClient := NewQUICClient(config.ServerAddr, tlsConf)
 
 	// QUIC 연결 고루틴
 	go func() {
-		connectCtx, connectCancel := context.WithTimeout(ctx, connectTimeout)
+		defer quicClient.Close()
+
+		connectCtx, connectCancel := context.WithTimeout(ctx, time.Duration(config.ConnectTimeout))
 		defer connectCancel()
 
 		if err := quicClient.Connect(connectCtx); err != nil {
@@ -47,25 +155,113 @@ func main() {
 		<-ctx.Done()
 	}()
 
-	// 패킷 스니퍼 초기화 (PCAP 파일 모드)
-	if err := packet.InitPacketSniffer(ctx); err != nil {
-		log.Fatal("Failed to initialize packet sniffer:", err)
+	return nil
+}
+
+// initPacketSniffer 설정한 모드로 패킷 스니퍼를 초기화합니다
+func initPacketSniffer(ctx context.Context, config Config) error {
+	packet.SetLocalPlayer(config.LocalPlayerID)
+	packet.SetBPFFilter(config.Filter)
+
+	if config.Mode == modeLive {
+		packet.SetInterfaces(config.Interfaces)
+		packet.SetInterfaceProbeDuration(time.Duration(config.Probe))
+		return packet.InitPacketSnifferLive(ctx)
 	}
-	defer packet.ClosePacketSniffer()
 
-	// 라이브 캡처를 원하는 경우 아래 주석을 해제하고 위 코드를 주석처리
-	/*
-		if err := packet.InitPacketSnifferLive(ctx); err != nil {
-			log.Fatal("Failed to initialize live packet sniffer:", err)
+	if len(config.PcapFiles) > 0 {
+		packet.SetPcapFiles(config.PcapFiles)
+	}
+
+	speed, err := packet.ParseReplaySpeed(config.Speed)
+	if err != nil {
+		return err
+	}
+	packet.SetReplaySpeed(speed)
+
+	return packet.InitPacketSniffer(ctx)
+}
+
+// logDamage 귀속된 피해를 로그로 출력합니다
+func logDamage(damage combat.Damage) {
+	switch {
+	case damage.Unattributed:
+		log.Printf("damage: %d -> target %d (unattributed)", damage.Amount, damage.TargetID)
+	case damage.Ambiguous:
+		log.Printf("damage: %d by %d -> target %d (ambiguous)", damage.Amount, damage.AttackerID, damage.TargetID)
+	default:
+		log.Printf("damage: %d by %d -> target %d", damage.Amount, damage.AttackerID, damage.TargetID)
+	}
+}
+
+// logMeter 주기적으로 진행 중인 전투의 DPS 미터를 출력합니다
+func logMeter(ctx context.Context, engine *combat.Engine, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if report, ok := engine.CurrentEncounter(); ok {
+				logEncounterReport(report)
+			}
 		}
-		defer packet.ClosePacketSniffer()
-	*/
+	}
+}
 
-	packet.StartPacketSniffer()
+// logEncounterReport 전투 보고서를 로그로 출력합니다
+func logEncounterReport(report combat.EncounterReport) {
+	var b strings.Builder
+	if err := combat.WriteEncounterReport(&b, report); err != nil {
+		log.Printf("Failed to format encounter report: %v", err)
+		return
+	}
 
-	// 시그널 대기 및 종료
-	<-sigCh
-	log.Println("🛑 Shutting down...")
-	cancel()
-	packet.StopPacketSniffer()
+	log.Printf("%s", b.String())
+}
+
+// loadProtocolProfile 프로토콜 프로필 파일이 있으면 선택한 프로필을 적용하고, 설정한 포트로 덮어씁니다
+func loadProtocolProfile(config Config) error {
+	profile := packet.DefaultProfile()
+
+	if _, err := os.Stat(config.ProtocolProfilePath); os.IsNotExist(err) {
+		log.Printf("Protocol profile file %s not found, using built-in default", config.ProtocolProfilePath)
+	} else {
+		profiles, err := packet.LoadProfiles(config.ProtocolProfilePath)
+		if err != nil {
+			return err
+		}
+
+		if profile, err = packet.SelectProfile(profiles, config.ProtocolProfile); err != nil {
+			return err
+		}
+	}
+
+	if config.Port != 0 {
+		profile.Port = uint16(config.Port)
+	}
+
+	if err := packet.UseProfile(profile); err != nil {
+		return err
+	}
+
+	log.Printf("Using protocol profile %s (game version: %s, port: %d)", profile.Name, profile.GameVersion, profile.Port)
+	return nil
+}
+
+// dumpCatalog 파서가 없는 세그먼트 타입 통계를 파일로 저장합니다
+func dumpCatalog(path string) {
+	if err := packet.DumpCatalogFile(path); err != nil {
+		log.Printf("Failed to dump unknown type catalog: %v", err)
+		return
+	}
+
+	if evicted := packet.CatalogEvicted(); evicted > 0 {
+		log.Printf("Unknown type catalog written to %s (%d rarely seen types evicted)", path, evicted)
+		return
+	}
+
+	log.Printf("Unknown type catalog written to %s", path)
 }
@@ -1,35 +1,23 @@
 package packet
 
-import (
-	"encoding/binary"
-	"fmt"
-	"strings"
-)
+import "fmt"
 
 type ActionData struct {
-	UserID    uint32
-	SkillName string
-	Key1      uint32
+	UserID    uint32 `layout:"u32,at=0"`
+	SkillName string `layout:"str32,at=8"`
+	Key1      uint32 `layout:"u32,skip=8"` // 스킬 이름 뒤 8바이트
 }
 
 const actionDataMinLength = 12
 
+var actionLayout = mustNewLayout[ActionData]("action")
+
 func parseAction(data []byte) (ActionData, error) {
 	if len(data) < actionDataMinLength {
 		return ActionData{}, fmt.Errorf("action packet too short: %d bytes, need at least %d", len(data), actionDataMinLength)
 	}
 
-	userID := binary.LittleEndian.Uint32(data[0:4])
-	skillNameLen := binary.LittleEndian.Uint32(data[8:12])
-	skillNameBytes := removeNullBytes(data[12 : 12+skillNameLen])
-	skillName := strings.TrimSpace(string(skillNameBytes))
-	key1 := binary.LittleEndian.Uint32(data[12+skillNameLen+8 : 12+skillNameLen+12])
-
-	return ActionData{
-		UserID:    userID,
-		SkillName: skillName,
-		Key1:      key1,
-	}, nil
+	return actionLayout.Decode(data)
 }
 
 func removeNullBytes(data []byte) []byte {
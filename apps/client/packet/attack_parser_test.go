@@ -0,0 +1,62 @@
+package packet
+
+import (
+	"testing"
+)
+
+var attackFixture = []byte{
+	0x01, 0x00, 0x00, 0x00, // 0: UserID
+	0xde, 0xad, 0xbe, 0xef,
+	0x64, 0x00, 0x00, 0x00, // 8: TargetID (100)
+	0xde, 0xad, 0xbe, 0xef,
+	0x0b, 0x00, 0x00, 0x00, // 16: Key1
+	0x0c, 0x00, 0x00, 0x00, // 20: Key2
+	0x01, 0x08, 0x00, 0x40, 0x10, 0x00, 0x00, // 24: 플래그 (crit, dot1, fire, dot4)
+	0x00, 0x00, 0x00, 0x00,
+}
+
+func TestParseAttack(t *testing.T) {
+	got, err := parseAttack(attackFixture)
+	if err != nil {
+		t.Fatalf("parseAttack: %v", err)
+	}
+
+	want := AttackData{
+		UserID:   1,
+		TargetID: 100,
+		Key1:     11,
+		Key2:     12,
+		Flags:    DamageFlags(0).With(FlagCrit).With(FlagDot1).With(FlagFire).With(FlagDot4),
+	}
+	if got != want {
+		t.Fatalf("parseAttack = %+v (%s), want %+v (%s)", got, got.Flags, want, want.Flags)
+	}
+
+	for _, length := range []int{attackDataLength - 1, attackDataLength + 1} {
+		data := make([]byte, length)
+		if _, err := parseAttack(data); err == nil {
+			t.Fatalf("parseAttack accepted %d bytes", length)
+		}
+	}
+}
+
+func BenchmarkParseAttack(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := parseAttack(attackFixture); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseAttackRegistry 레지스트리를 거치면 결과가 any로 박싱되는 비용이 더해집니다
+func BenchmarkParseAttackRegistry(b *testing.B) {
+	parser := builtinParsers["attack"]
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := parser.Parse(attackFixture); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// 압축 해제 결과의 최대 크기 (비정상 데이터로 인한 메모리 폭주 방지)
+const maxDecompressedSegmentLength = 4 * 1024 * 1024
+
+var brotliReaderPool = sync.Pool{
+	New: func() any {
+		return brotli.NewReader(nil)
+	},
+}
+
+// decompressBrotli Brotli 스트림을 해제합니다.
+// 반환된 슬라이스는 새로 할당되므로 호출자가 보관해도 안전합니다.
+func decompressBrotli(data []byte) ([]byte, error) {
+	reader := brotliReaderPool.Get().(*brotli.Reader)
+	defer brotliReaderPool.Put(reader)
+
+	if err := reader.Reset(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSegmentLength+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decompressed) > maxDecompressedSegmentLength {
+		return nil, fmt.Errorf("decompressed segment exceeds %d bytes", maxDecompressedSegmentLength)
+	}
+
+	return decompressed, nil
+}
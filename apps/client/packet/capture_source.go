@@ -0,0 +1,241 @@
+package packet
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+const (
+	// 캡처 고루틴들과 재조립 고루틴 사이의 패킷 큐 길이
+	packetQueueLength = 4096
+	// 여러 인터페이스에서 같은 패킷이 보일 수 있는 최대 시간 차
+	duplicateWindow = 500 * time.Millisecond
+)
+
+var errSourceClosed = errors.New("capture source closed")
+
+// packetHandle 캡처 소스가 사용하는 pcap 핸들의 기능
+type packetHandle interface {
+	gopacket.PacketDataSource
+	LinkType() layers.LinkType
+	Stats() (*pcap.Stats, error)
+	Close()
+}
+
+// captureSource 캡처 핸들 하나 (라이브 인터페이스 또는 PCAP 파일).
+// PCAP 파일의 핸들은 뒤로 탐색할 때 읽기 고루틴이 바꾸므로 mu로 보호합니다.
+type captureSource struct {
+	name string
+	live bool
+
+	mu     sync.Mutex
+	handle packetHandle
+	closed bool
+
+	packets    atomic.Uint64
+	duplicates atomic.Uint64
+}
+
+// capturedPacket 재조립 고루틴으로 보내는 패킷과 그 패킷을 읽은 캡처 핸들
+type capturedPacket struct {
+	packet  gopacket.Packet
+	source  *captureSource
+	restart bool // PCAP 재생을 처음부터 다시 시작하므로 재조립기를 초기화해야 함
+}
+
+// InterfaceStats 캡처 핸들 하나의 통계
+type InterfaceStats struct {
+	Name       string
+	Live       bool
+	Packets    uint64 // 읽은 패킷 수
+	Duplicates uint64 // 다른 인터페이스에서 이미 본 패킷 수
+	Received   int    // libpcap이 받은 패킷 수 (라이브 캡처)
+	Dropped    int    // 버퍼가 가득 차 libpcap이 버린 패킷 수 (라이브 캡처)
+	IfDropped  int    // 인터페이스가 버린 패킷 수 (라이브 캡처)
+}
+
+// CaptureStats 캡처 핸들별 통계를 반환합니다
+func CaptureStats() []InterfaceStats {
+	stats := make([]InterfaceStats, 0, len(sources))
+	for _, source := range sources {
+		stat := InterfaceStats{
+			Name:       source.name,
+			Live:       source.live,
+			Packets:    source.packets.Load(),
+			Duplicates: source.duplicates.Load(),
+		}
+
+		if source.live {
+			if pcapStats, err := source.stats(); err == nil {
+				stat.Received = pcapStats.PacketsReceived
+				stat.Dropped = pcapStats.PacketsDropped
+				stat.IfDropped = pcapStats.PacketsIfDropped
+			}
+		}
+
+		stats = append(stats, stat)
+	}
+
+	return stats
+}
+
+// current 현재 캡처 핸들을 반환합니다
+func (s *captureSource) current() packetHandle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.handle
+}
+
+// stats 핸들이 닫히지 않았으면 libpcap 통계를 반환합니다
+func (s *captureSource) stats() (*pcap.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, errSourceClosed
+	}
+	return s.handle.Stats()
+}
+
+// close 핸들을 닫습니다. 여러 번 호출해도 됩니다.
+func (s *captureSource) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		s.handle.Close()
+	}
+}
+
+// logCaptureStats 캡처 핸들별 통계를 로그로 출력합니다
+func logCaptureStats() {
+	for _, stat := range CaptureStats() {
+		if !stat.Live {
+			log.Printf("Capture stats %s: %d packets", stat.Name, stat.Packets)
+			continue
+		}
+
+		log.Printf("Capture stats %s: %d packets, %d duplicates, %d received, %d dropped, %d dropped by interface",
+			stat.Name, stat.Packets, stat.Duplicates, stat.Received, stat.Dropped, stat.IfDropped)
+	}
+}
+
+// readSources 모든 캡처 핸들의 패킷을 out으로 보내고, 다 읽으면 out을 닫습니다.
+// 라이브 인터페이스는 동시에 읽고, PCAP 파일은 재생 시계에 맞춰 순서대로 읽습니다.
+func readSources(out chan<- capturedPacket) {
+	defer close(out)
+
+	var files []*captureSource
+	var readers sync.WaitGroup
+	for _, source := range sources {
+		if !source.live {
+			files = append(files, source)
+			continue
+		}
+
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			source.read(out)
+		}()
+	}
+	readers.Wait()
+
+	if len(files) > 0 {
+		readReplay(out, files)
+	}
+}
+
+// read 핸들의 패킷을 out으로 보냅니다. ctx가 취소되면 false를 반환합니다.
+func (s *captureSource) read(out chan<- capturedPacket) bool {
+	handle := s.current()
+	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
+
+	for packet := range packetSource.Packets() {
+		s.packets.Add(1)
+
+		select {
+		case out <- capturedPacket{packet: packet, source: s}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+// packetDeduplicator 여러 인터페이스에서 같은 TCP 세그먼트가 캡처되면 처음 본 것만 통과시킵니다.
+// 재조립 고루틴에서만 사용합니다.
+type packetDeduplicator struct {
+	seen      map[uint64]time.Time
+	lastSweep time.Time
+}
+
+func newPacketDeduplicator() *packetDeduplicator {
+	return &packetDeduplicator{
+		seen: make(map[uint64]time.Time),
+	}
+}
+
+// duplicate duplicateWindow 안에 같은 세그먼트를 이미 봤는지 확인합니다.
+// 인터페이스마다 다른 링크 계층과 TTL은 비교하지 않습니다.
+func (d *packetDeduplicator) duplicate(net gopacket.Flow, tcp *layers.TCP, timestamp time.Time) bool {
+	d.sweep(timestamp)
+
+	hash := fnv.New64a()
+	hash.Write(net.Src().Raw())
+	hash.Write(net.Dst().Raw())
+
+	var header [14]byte
+	binary.BigEndian.PutUint16(header[0:], uint16(tcp.SrcPort))
+	binary.BigEndian.PutUint16(header[2:], uint16(tcp.DstPort))
+	binary.BigEndian.PutUint32(header[4:], tcp.Seq)
+	binary.BigEndian.PutUint32(header[8:], tcp.Ack)
+	header[12] = tcpFlags(tcp)
+	header[13] = byte(len(tcp.Payload))
+	hash.Write(header[:])
+	hash.Write(tcp.Payload)
+
+	key := hash.Sum64()
+	if seen, ok := d.seen[key]; ok && timestamp.Sub(seen).Abs() <= duplicateWindow {
+		return true
+	}
+
+	d.seen[key] = timestamp
+	return false
+}
+
+func (d *packetDeduplicator) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < duplicateWindow {
+		return
+	}
+	d.lastSweep = now
+
+	cutoff := now.Add(-duplicateWindow)
+	for key, seen := range d.seen {
+		if seen.Before(cutoff) {
+			delete(d.seen, key)
+		}
+	}
+}
+
+func tcpFlags(tcp *layers.TCP) byte {
+	var flags byte
+	for i, set := range [...]bool{tcp.FIN, tcp.SYN, tcp.RST, tcp.PSH, tcp.ACK, tcp.URG, tcp.ECE, tcp.CWR} {
+		if set {
+			flags |= 1 << i
+		}
+	}
+	return flags
+}
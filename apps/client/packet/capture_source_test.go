@@ -0,0 +1,157 @@
+package packet
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// testTCPPacket 클라이언트와 게임 서버 사이의 TCP 패킷을 만듭니다
+func testTCPPacket(t *testing.T, timestamp time.Time, fromServer bool, tcp layers.TCP, payload []byte) gopacket.Packet {
+	t.Helper()
+
+	client, server := net.IPv4(10, 0, 0, 2), net.IPv4(10, 0, 0, 1)
+	clientPort, serverPort := layers.TCPPort(50000), layers.TCPPort(currentProfile().Port)
+
+	ip := layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: client, DstIP: server}
+	tcp.SrcPort, tcp.DstPort = clientPort, serverPort
+	if fromServer {
+		ip.SrcIP, ip.DstIP = server, client
+		tcp.SrcPort, tcp.DstPort = serverPort, clientPort
+	}
+	tcp.Window = 65535
+	if err := tcp.SetNetworkLayerForChecksum(&ip); err != nil {
+		t.Fatal(err)
+	}
+
+	ethernet := layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0, 1, 2, 3, 4, 5},
+		DstMAC:       net.HardwareAddr{0, 1, 2, 3, 4, 6},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	options := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, options, &ethernet, &ip, &tcp, gopacket.Payload(payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	packet := gopacket.NewPacket(buf.Bytes(), layers.LayerTypeEthernet, gopacket.Default)
+	packet.Metadata().CaptureInfo = gopacket.CaptureInfo{Timestamp: timestamp, CaptureLength: len(buf.Bytes()), Length: len(buf.Bytes())}
+	return packet
+}
+
+// testHandle 미리 만든 패킷들을 돌려주는 캡처 핸들
+type testHandle struct {
+	packets []gopacket.Packet
+	stats   pcap.Stats
+	closed  bool
+}
+
+func (h *testHandle) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	if len(h.packets) == 0 {
+		return nil, gopacket.CaptureInfo{}, io.EOF
+	}
+
+	packet := h.packets[0]
+	h.packets = h.packets[1:]
+	return packet.Data(), packet.Metadata().CaptureInfo, nil
+}
+
+func (h *testHandle) LinkType() layers.LinkType   { return layers.LinkTypeEthernet }
+func (h *testHandle) Stats() (*pcap.Stats, error) { return &h.stats, nil }
+func (h *testHandle) Close()                      { h.closed = true }
+
+func TestPacketDeduplicatorWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	segment := func(seq uint32) layers.TCP { return layers.TCP{Seq: seq, Ack: 101, ACK: true, PSH: true} }
+
+	steps := []struct {
+		name       string
+		offset     time.Duration
+		fromServer bool
+		tcp        layers.TCP
+		want       bool
+	}{
+		{"first sighting", 0, true, segment(1000), false},
+		{"same segment on another interface", 300 * time.Millisecond, true, segment(1000), true},
+		{"at the window edge", duplicateWindow, true, segment(1000), true},
+		{"other direction", duplicateWindow, false, segment(1000), false},
+		{"other sequence number", duplicateWindow, true, segment(2000), false},
+		{"outside the window", duplicateWindow + time.Millisecond, true, segment(1000), false},
+		{"within the window of the resent segment", duplicateWindow + 100*time.Millisecond, true, segment(1000), true},
+	}
+
+	dedup := newPacketDeduplicator()
+	for _, step := range steps {
+		packet := testTCPPacket(t, start.Add(step.offset), step.fromServer, step.tcp, []byte{0x01, 0x02, 0x03})
+		tcp := packet.Layer(layers.LayerTypeTCP).(*layers.TCP)
+
+		if got := dedup.duplicate(packet.NetworkLayer().NetworkFlow(), tcp, packet.Metadata().Timestamp); got != step.want {
+			t.Fatalf("%s: duplicate = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestCaptureStatsCountsPerSource(t *testing.T) {
+	previousCtx, previousAssembler, previousSources := ctx, assembler, sources
+	t.Cleanup(func() { ctx, assembler, sources = previousCtx, previousAssembler, previousSources })
+
+	ctx = context.Background()
+	assembler = newAssembler()
+
+	start := time.Unix(1700000000, 0)
+	segment := func(offset time.Duration, seq uint32) gopacket.Packet {
+		return testTCPPacket(t, start.Add(offset), true, layers.TCP{Seq: seq, Ack: 101, ACK: true, PSH: true}, []byte{0x01, 0x02, 0x03})
+	}
+
+	// 두 인터페이스가 같은 세그먼트 두 개를 봅니다. 두 번째 세그먼트는 중복 판정 시간 밖에서 다시 보입니다.
+	first := &captureSource{name: "eth0", live: true, handle: &testHandle{
+		packets: []gopacket.Packet{segment(0, 1000), segment(10*time.Millisecond, 1003)},
+		stats:   pcap.Stats{PacketsReceived: 10, PacketsDropped: 1},
+	}}
+	second := &captureSource{name: "wlan0", live: true, handle: &testHandle{
+		packets: []gopacket.Packet{segment(100*time.Millisecond, 1000), segment(2*time.Second, 1003)},
+		stats:   pcap.Stats{PacketsReceived: 20, PacketsIfDropped: 2},
+	}}
+	sources = []*captureSource{first, second}
+
+	captured := make(chan capturedPacket, 4)
+	for _, source := range sources {
+		if !source.read(captured) {
+			t.Fatalf("read %s was cancelled", source.name)
+		}
+	}
+	close(captured)
+
+	dedup := newPacketDeduplicator()
+	for packet := range captured {
+		assemblePacket(packet, dedup)
+	}
+
+	want := []InterfaceStats{
+		{Name: "eth0", Live: true, Packets: 2, Duplicates: 0, Received: 10, Dropped: 1},
+		{Name: "wlan0", Live: true, Packets: 2, Duplicates: 1, Received: 20, IfDropped: 2},
+	}
+	got := CaptureStats()
+	if len(got) != len(want) {
+		t.Fatalf("CaptureStats = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("CaptureStats[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// 닫힌 핸들의 libpcap 통계는 읽지 않습니다
+	second.close()
+	if stat := CaptureStats()[1]; stat.Received != 0 || stat.Packets != 2 || stat.Duplicates != 1 {
+		t.Fatalf("stats after close = %+v, want counters without libpcap stats", stat)
+	}
+}
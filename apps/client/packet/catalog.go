@@ -0,0 +1,300 @@
+package packet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	catalogSampleCount     = 16   // 타입별로 보관할 최근 샘플 수
+	catalogSampleMaxLength = 4096 // 샘플 하나에 보관할 최대 바이트 수
+	catalogMaxLengthKinds  = 64   // 대표 길이 계산을 위해 추적할 서로 다른 길이 수
+
+	// 손상된 데이터처럼 타입 번호가 계속 새로 나오는 경우에도 메모리가 늘지 않도록 하는 한도
+	catalogMaxEntries     = 256             // 추적할 최대 타입 수 (넘으면 가장 적게 본 타입을 버림)
+	catalogMaxSampleBytes = 4 * 1024 * 1024 // 모든 타입의 샘플을 합친 최대 바이트 수
+)
+
+// CatalogSample 파서가 없는 세그먼트의 원본 샘플
+type CatalogSample struct {
+	Timestamp time.Time
+	Direction Direction
+	Server    netip.AddrPort
+	Length    int // 원본 길이 (Data는 catalogSampleMaxLength에서 잘릴 수 있음)
+	Data      []byte
+}
+
+// CatalogEntry 파서가 없는 데이터 타입 하나의 통계
+type CatalogEntry struct {
+	Type          int
+	Count         uint64
+	MinLength     int
+	MaxLength     int
+	TypicalLength int // 가장 자주 나온 길이
+	FirstSeen     time.Time
+	LastSeen      time.Time
+	Samples       []CatalogSample // 오래된 순
+}
+
+type catalogEntry struct {
+	CatalogEntry
+	lengthCounts map[int]uint64
+	samples      [catalogSampleCount]CatalogSample
+	next         int // 다음 샘플을 쓸 위치 (누적)
+	filled       int // 보관 중인 샘플 수
+	sampleBytes  int
+}
+
+var (
+	catalogMu          sync.Mutex
+	catalog            = make(map[int]*catalogEntry)
+	catalogSampleBytes int // 모든 타입이 보관 중인 샘플 바이트 수
+	catalogEvicted     uint64
+)
+
+// recordUnknown 등록된 파서가 없는 세그먼트를 카탈로그에 기록합니다
+func recordUnknown(data AnalyzedData, timestamp time.Time) {
+	length := len(data.Content)
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	entry, ok := catalog[data.Type]
+	if !ok {
+		if len(catalog) >= catalogMaxEntries {
+			evictCatalogEntry()
+		}
+
+		entry = &catalogEntry{
+			CatalogEntry: CatalogEntry{
+				Type:      data.Type,
+				MinLength: length,
+				MaxLength: length,
+				FirstSeen: timestamp,
+			},
+			lengthCounts: make(map[int]uint64),
+		}
+		catalog[data.Type] = entry
+	}
+
+	entry.Count++
+	entry.LastSeen = timestamp
+	entry.MinLength = min(entry.MinLength, length)
+	entry.MaxLength = max(entry.MaxLength, length)
+
+	if _, tracked := entry.lengthCounts[length]; tracked || len(entry.lengthCounts) < catalogMaxLengthKinds {
+		entry.lengthCounts[length]++
+	}
+
+	// 링이 가득 차면 가장 오래된 샘플 자리를 재사용합니다
+	if entry.filled == catalogSampleCount {
+		entry.dropOldestSample()
+	}
+
+	content := data.Content[:min(length, catalogSampleMaxLength)]
+	for catalogSampleBytes+len(content) > catalogMaxSampleBytes {
+		if !dropLargestSamples() {
+			break
+		}
+	}
+
+	sample := &entry.samples[entry.next%catalogSampleCount]
+	sample.Timestamp = timestamp
+	sample.Direction = data.Direction
+	sample.Server = data.Server
+	sample.Length = length
+	sample.Data = make([]byte, len(content))
+	copy(sample.Data, content)
+	entry.next++
+	entry.filled++
+	entry.sampleBytes += len(sample.Data)
+	catalogSampleBytes += len(sample.Data)
+}
+
+// evictCatalogEntry 가장 적게 본 타입(같으면 가장 오래전에 본 타입)을 카탈로그에서 제거합니다
+func evictCatalogEntry() {
+	var victim *catalogEntry
+	for _, entry := range catalog {
+		if victim == nil || entry.Count < victim.Count ||
+			(entry.Count == victim.Count && entry.LastSeen.Before(victim.LastSeen)) {
+			victim = entry
+		}
+	}
+
+	if victim == nil {
+		return
+	}
+
+	catalogSampleBytes -= victim.sampleBytes
+	delete(catalog, victim.Type)
+	catalogEvicted++
+}
+
+// dropLargestSamples 샘플을 가장 많이 보관한 타입의 가장 오래된 샘플을 버립니다. 버릴 샘플이 없으면 false를 반환합니다.
+func dropLargestSamples() bool {
+	var victim *catalogEntry
+	for _, entry := range catalog {
+		if entry.filled > 0 && (victim == nil || entry.sampleBytes > victim.sampleBytes) {
+			victim = entry
+		}
+	}
+
+	if victim == nil {
+		return false
+	}
+
+	victim.dropOldestSample()
+	return true
+}
+
+func (e *catalogEntry) dropOldestSample() {
+	sample := &e.samples[(e.next-e.filled)%catalogSampleCount]
+	e.sampleBytes -= len(sample.Data)
+	catalogSampleBytes -= len(sample.Data)
+
+	*sample = CatalogSample{}
+	e.filled--
+}
+
+// CatalogSnapshot 카탈로그의 복사본을 출현 횟수가 많은 순으로 반환합니다
+func CatalogSnapshot() []CatalogEntry {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	entries := make([]CatalogEntry, 0, len(catalog))
+	for _, entry := range catalog {
+		entries = append(entries, entry.snapshot())
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Type < entries[j].Type
+	})
+
+	return entries
+}
+
+// ResetCatalog 카탈로그를 비웁니다
+func ResetCatalog() {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	catalog = make(map[int]*catalogEntry)
+	catalogSampleBytes = 0
+	catalogEvicted = 0
+}
+
+// CatalogEvicted 한도를 넘어 카탈로그에서 제거된 타입 수를 반환합니다
+func CatalogEvicted() uint64 {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	return catalogEvicted
+}
+
+func (e *catalogEntry) snapshot() CatalogEntry {
+	out := e.CatalogEntry
+
+	var typicalCount uint64
+	for length, count := range e.lengthCounts {
+		if count > typicalCount || (count == typicalCount && length < out.TypicalLength) {
+			out.TypicalLength = length
+			typicalCount = count
+		}
+	}
+
+	out.Samples = make([]CatalogSample, 0, e.filled)
+	for i := e.next - e.filled; i < e.next; i++ {
+		sample := e.samples[i%catalogSampleCount]
+		sample.Data = append([]byte(nil), sample.Data...)
+		out.Samples = append(out.Samples, sample)
+	}
+
+	return out
+}
+
+type catalogSampleJSON struct {
+	Timestamp time.Time `json:"timestamp"`
+	Direction string    `json:"direction"`
+	Server    string    `json:"server,omitempty"`
+	Length    int       `json:"length"`
+	Hex       string    `json:"hex"`
+}
+
+type catalogEntryJSON struct {
+	Type          int                 `json:"type"`
+	Count         uint64              `json:"count"`
+	MinLength     int                 `json:"min_length"`
+	MaxLength     int                 `json:"max_length"`
+	TypicalLength int                 `json:"typical_length"`
+	FirstSeen     time.Time           `json:"first_seen"`
+	LastSeen      time.Time           `json:"last_seen"`
+	Samples       []catalogSampleJSON `json:"samples"`
+}
+
+// DumpCatalog 카탈로그를 JSON으로 w에 기록합니다 (샘플은 16진수 문자열)
+func DumpCatalog(w io.Writer) error {
+	entries := CatalogSnapshot()
+
+	out := make([]catalogEntryJSON, 0, len(entries))
+	for _, entry := range entries {
+		samples := make([]catalogSampleJSON, 0, len(entry.Samples))
+		for _, sample := range entry.Samples {
+			server := ""
+			if sample.Server.IsValid() {
+				server = sample.Server.String()
+			}
+
+			samples = append(samples, catalogSampleJSON{
+				Timestamp: sample.Timestamp,
+				Direction: sample.Direction.String(),
+				Server:    server,
+				Length:    sample.Length,
+				Hex:       hex.EncodeToString(sample.Data),
+			})
+		}
+
+		out = append(out, catalogEntryJSON{
+			Type:          entry.Type,
+			Count:         entry.Count,
+			MinLength:     entry.MinLength,
+			MaxLength:     entry.MaxLength,
+			TypicalLength: entry.TypicalLength,
+			FirstSeen:     entry.FirstSeen,
+			LastSeen:      entry.LastSeen,
+			Samples:       samples,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// DumpCatalogFile 카탈로그를 path 파일에 JSON으로 저장합니다
+func DumpCatalogFile(path string) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create catalog dump %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close catalog dump %s: %w", path, closeErr)
+		}
+	}()
+
+	if err := DumpCatalog(file); err != nil {
+		return fmt.Errorf("failed to write catalog dump %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func recordUnknownN(dataType, length, n int, timestamp time.Time) {
+	content := bytes.Repeat([]byte{byte(dataType)}, length)
+	for range n {
+		recordUnknown(AnalyzedData{Type: dataType, Content: content}, timestamp)
+	}
+}
+
+func TestCatalogKeepsRecentSamples(t *testing.T) {
+	ResetCatalog()
+	defer ResetCatalog()
+
+	base := time.Unix(1700000000, 0)
+	for i := range catalogSampleCount + 4 {
+		recordUnknown(AnalyzedData{Type: 7, Content: []byte{byte(i), 0, 0}}, base.Add(time.Duration(i)*time.Second))
+	}
+	recordUnknown(AnalyzedData{Type: 7, Content: []byte{0xff}}, base.Add(time.Minute))
+
+	entries := CatalogSnapshot()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Count != catalogSampleCount+5 || entry.MinLength != 1 || entry.MaxLength != 3 || entry.TypicalLength != 3 {
+		t.Fatalf("entry stats = %+v", entry)
+	}
+
+	if len(entry.Samples) != catalogSampleCount {
+		t.Fatalf("kept %d samples, want %d", len(entry.Samples), catalogSampleCount)
+	}
+
+	// 오래된 순으로, 링을 덮어쓴 뒤의 최근 샘플만 남아야 합니다
+	if first := entry.Samples[0].Data[0]; first != 5 {
+		t.Fatalf("oldest sample starts with %d, want 5", first)
+	}
+	if last := entry.Samples[len(entry.Samples)-1].Data; !bytes.Equal(last, []byte{0xff}) {
+		t.Fatalf("newest sample = %x, want ff", last)
+	}
+}
+
+func TestCatalogEvictsLeastSeenType(t *testing.T) {
+	ResetCatalog()
+	defer ResetCatalog()
+
+	base := time.Unix(1700000000, 0)
+
+	// 자주 나오는 타입은 새 타입이 계속 들어와도 남아야 합니다
+	recordUnknownN(1, 8, 10, base)
+	for dataType := 2; dataType <= catalogMaxEntries+50; dataType++ {
+		recordUnknownN(dataType, 8, 1, base.Add(time.Duration(dataType)*time.Millisecond))
+	}
+
+	entries := CatalogSnapshot()
+	if len(entries) != catalogMaxEntries {
+		t.Fatalf("catalog has %d entries, want %d", len(entries), catalogMaxEntries)
+	}
+
+	if entries[0].Type != 1 || entries[0].Count != 10 {
+		t.Fatalf("most seen entry = type %d count %d, want type 1 count 10", entries[0].Type, entries[0].Count)
+	}
+
+	if evicted := CatalogEvicted(); evicted != 50 {
+		t.Fatalf("CatalogEvicted() = %d, want 50", evicted)
+	}
+
+	// 같은 횟수면 가장 오래전에 본 타입부터 버려집니다
+	for _, entry := range entries {
+		if entry.Type >= 2 && entry.Type <= 51 {
+			t.Fatalf("type %d should have been evicted", entry.Type)
+		}
+	}
+}
+
+func TestCatalogCapsSampleBytes(t *testing.T) {
+	ResetCatalog()
+	defer ResetCatalog()
+
+	base := time.Unix(1700000000, 0)
+	types := catalogMaxSampleBytes/(catalogSampleCount*catalogSampleMaxLength) + 8
+
+	for dataType := 1; dataType <= types; dataType++ {
+		recordUnknownN(dataType, catalogSampleMaxLength*2, catalogSampleCount, base)
+	}
+
+	total := 0
+	for _, entry := range CatalogSnapshot() {
+		for _, sample := range entry.Samples {
+			if sample.Length != catalogSampleMaxLength*2 || len(sample.Data) != catalogSampleMaxLength {
+				t.Fatalf("type %d sample length %d/%d, want truncated to %d", entry.Type, len(sample.Data), sample.Length, catalogSampleMaxLength)
+			}
+			total += len(sample.Data)
+		}
+	}
+
+	if total > catalogMaxSampleBytes {
+		t.Fatalf("catalog keeps %d sample bytes, want at most %d", total, catalogMaxSampleBytes)
+	}
+
+	if total != catalogSampleBytes {
+		t.Fatalf("sample byte accounting = %d, actual %d", catalogSampleBytes, total)
+	}
+
+	// 샘플을 가장 많이 가진 타입부터 덜어내므로 나중에 들어온 타입도 비슷한 몫의 샘플을 가집니다
+	entries := CatalogSnapshot()
+	fewest, most := catalogSampleCount, 0
+	for _, entry := range entries {
+		fewest = min(fewest, len(entry.Samples))
+		most = max(most, len(entry.Samples))
+	}
+
+	if len(entries) != types || fewest == 0 || most-fewest > 2 {
+		t.Fatalf("%d types keep between %d and %d samples, want an even share", len(entries), fewest, most)
+	}
+}
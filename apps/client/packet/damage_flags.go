@@ -0,0 +1,162 @@
+package packet
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// DamageFlag 공격 플래그 하나 (DamageFlags의 비트 위치)
+type DamageFlag uint8
+
+const (
+	FlagCrit DamageFlag = iota
+	FlagWhat1
+	FlagUnguarded
+	FlagBreak
+	FlagWhat05
+	FlagWhat06
+	FlagFirstHit
+	FlagDefaultAttack
+	FlagMultiAttack
+	FlagPower
+	FlagFast
+	FlagDot1
+	FlagDot2
+	FlagDot3
+	FlagAddHit
+	FlagBleed
+	FlagDark
+	FlagFire
+	FlagHoly
+	FlagIce
+	FlagElectric
+	FlagPoison
+	FlagMind
+	FlagDot4
+
+	damageFlagCount
+)
+
+var damageFlagNames = [damageFlagCount]string{
+	FlagCrit:          "crit",
+	FlagWhat1:         "what1",
+	FlagUnguarded:     "unguarded",
+	FlagBreak:         "break",
+	FlagWhat05:        "what05",
+	FlagWhat06:        "what06",
+	FlagFirstHit:      "first_hit",
+	FlagDefaultAttack: "default_attack",
+	FlagMultiAttack:   "multi_attack",
+	FlagPower:         "power",
+	FlagFast:          "fast",
+	FlagDot1:          "dot1",
+	FlagDot2:          "dot2",
+	FlagDot3:          "dot3",
+	FlagAddHit:        "add_hit",
+	FlagBleed:         "bleed",
+	FlagDark:          "dark",
+	FlagFire:          "fire",
+	FlagHoly:          "holy",
+	FlagIce:           "ice",
+	FlagElectric:      "electric",
+	FlagPoison:        "poison",
+	FlagMind:          "mind",
+	FlagDot4:          "dot4",
+}
+
+func (f DamageFlag) String() string {
+	if f >= damageFlagCount {
+		return fmt.Sprintf("flag%d", uint8(f))
+	}
+	return damageFlagNames[f]
+}
+
+// ParseDamageFlag 플래그 이름으로 DamageFlag를 찾습니다
+func ParseDamageFlag(name string) (DamageFlag, bool) {
+	for i, flagName := range damageFlagNames {
+		if flagName == name {
+			return DamageFlag(i), true
+		}
+	}
+
+	return 0, false
+}
+
+// DamageFlags 공격 플래그 집합
+type DamageFlags uint32
+
+// Has flag가 켜져 있는지 확인합니다
+func (f DamageFlags) Has(flag DamageFlag) bool {
+	return f&(1<<flag) != 0
+}
+
+// With flag를 켠 집합을 반환합니다
+func (f DamageFlags) With(flag DamageFlag) DamageFlags {
+	return f | 1<<flag
+}
+
+// dotFlags 지속 피해 틱을 나타내는 플래그들
+const dotFlags = DamageFlags(1<<FlagDot1 | 1<<FlagDot2 | 1<<FlagDot3 | 1<<FlagDot4)
+
+// IsDoT 지속 피해(DoT) 틱인지 확인합니다
+func (f DamageFlags) IsDoT() bool {
+	return f&dotFlags != 0
+}
+
+// All 켜져 있는 플래그를 비트 순서대로 순회합니다
+func (f DamageFlags) All() iter.Seq[DamageFlag] {
+	return func(yield func(DamageFlag) bool) {
+		for flag := DamageFlag(0); flag < damageFlagCount; flag++ {
+			if f.Has(flag) && !yield(flag) {
+				return
+			}
+		}
+	}
+}
+
+// String 켜져 있는 플래그 이름을 "|"로 이어 반환합니다
+func (f DamageFlags) String() string {
+	var b strings.Builder
+	for flag := range f.All() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString(flag.String())
+	}
+
+	return b.String()
+}
+
+// MarshalJSON 기존 map[string]bool 형식과 같이 모든 플래그 이름을 키로 기록합니다
+func (f DamageFlags) MarshalJSON() ([]byte, error) {
+	flags := make(map[string]bool, damageFlagCount)
+	for flag := DamageFlag(0); flag < damageFlagCount; flag++ {
+		flags[flag.String()] = f.Has(flag)
+	}
+
+	return json.Marshal(flags)
+}
+
+func (f *DamageFlags) UnmarshalJSON(data []byte) error {
+	var flags map[string]bool
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return err
+	}
+
+	var out DamageFlags
+	for name, set := range flags {
+		flag, ok := ParseDamageFlag(name)
+		if !ok {
+			return fmt.Errorf("unknown damage flag %q", name)
+		}
+
+		if set {
+			out = out.With(flag)
+		}
+	}
+
+	*f = out
+	return nil
+}
@@ -0,0 +1,121 @@
+package packet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDamageFlagsString(t *testing.T) {
+	tests := []struct {
+		flags DamageFlags
+		want  string
+	}{
+		{0, ""},
+		{DamageFlags(0).With(FlagCrit), "crit"},
+		{DamageFlags(0).With(FlagFire).With(FlagCrit).With(FlagDot4), "crit|fire|dot4"},
+		{DamageFlags(1<<damageFlagCount - 1), strings.Join(damageFlagNames[:], "|")},
+		// 정의되지 않은 상위 비트는 이름에 나오지 않습니다
+		{DamageFlags(1 << 31).With(FlagBreak), "break"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.flags.String(); got != tt.want {
+			t.Errorf("DamageFlags(%#x).String() = %q, want %q", uint32(tt.flags), got, tt.want)
+		}
+	}
+}
+
+func TestDamageFlagNames(t *testing.T) {
+	seen := make(map[string]bool)
+	for flag := DamageFlag(0); flag < damageFlagCount; flag++ {
+		name := flag.String()
+		if name == "" || seen[name] {
+			t.Fatalf("flag %d has empty or duplicate name %q", flag, name)
+		}
+		seen[name] = true
+
+		if parsed, ok := ParseDamageFlag(name); !ok || parsed != flag {
+			t.Fatalf("ParseDamageFlag(%q) = %d, %v, want %d", name, parsed, ok, flag)
+		}
+	}
+
+	if got := damageFlagCount.String(); got != "flag24" {
+		t.Fatalf("unknown flag String() = %q, want flag24", got)
+	}
+	if _, ok := ParseDamageFlag("heal"); ok {
+		t.Fatal("ParseDamageFlag accepted an unknown name")
+	}
+}
+
+func TestDamageFlagsIsDoT(t *testing.T) {
+	for flag := DamageFlag(0); flag < damageFlagCount; flag++ {
+		want := flag == FlagDot1 || flag == FlagDot2 || flag == FlagDot3 || flag == FlagDot4
+		if got := DamageFlags(0).With(flag).IsDoT(); got != want {
+			t.Errorf("%s IsDoT() = %v, want %v", flag, got, want)
+		}
+	}
+}
+
+func TestDamageFlagsJSONRoundTrip(t *testing.T) {
+	for _, flags := range []DamageFlags{
+		0,
+		DamageFlags(0).With(FlagCrit),
+		DamageFlags(0).With(FlagUnguarded).With(FlagHoly).With(FlagDot4),
+		DamageFlags(1<<damageFlagCount - 1),
+	} {
+		data, err := json.Marshal(flags)
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", flags, err)
+		}
+
+		// 예전 map[string]bool 형식과 같이 모든 플래그가 키로 기록되어야 합니다
+		var asMap map[string]bool
+		if err := json.Unmarshal(data, &asMap); err != nil {
+			t.Fatalf("flags JSON is not an object: %s", data)
+		}
+		if len(asMap) != int(damageFlagCount) {
+			t.Fatalf("flags JSON has %d keys, want %d", len(asMap), damageFlagCount)
+		}
+		for flag := DamageFlag(0); flag < damageFlagCount; flag++ {
+			if asMap[flag.String()] != flags.Has(flag) {
+				t.Fatalf("flags JSON %s: %s = %v", data, flag, asMap[flag.String()])
+			}
+		}
+
+		var decoded DamageFlags
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if decoded != flags {
+			t.Fatalf("round trip %s -> %s -> %s", flags, data, decoded)
+		}
+	}
+}
+
+func TestDamageFlagsUnmarshalJSON(t *testing.T) {
+	var flags DamageFlags
+	if err := json.Unmarshal([]byte(`{"crit": true, "fire": false}`), &flags); err != nil {
+		t.Fatalf("Unmarshal partial map: %v", err)
+	}
+	if flags != DamageFlags(0).With(FlagCrit) {
+		t.Fatalf("partial map decoded to %s, want crit", flags)
+	}
+
+	for _, input := range []string{`{"heal": true}`, `["crit"]`, `3`} {
+		if err := json.Unmarshal([]byte(input), &flags); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want error", input)
+		}
+	}
+
+	// AttackData 안에서도 같은 형식으로 직렬화됩니다
+	data, err := json.Marshal(AttackData{UserID: 1, Flags: DamageFlags(0).With(FlagBreak)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attack AttackData
+	if err := json.Unmarshal(data, &attack); err != nil || attack.UserID != 1 || attack.Flags != DamageFlags(0).With(FlagBreak) {
+		t.Fatalf("AttackData round trip = %+v, %v", attack, err)
+	}
+}
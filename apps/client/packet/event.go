@@ -0,0 +1,155 @@
+package packet
+
+import (
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// Event 파싱된 세그먼트 하나를 나타내는 이벤트
+type Event struct {
+	Type      int            // 세그먼트 데이터 타입
+	Name      string         // 파서 이름 (ParserInfo.Name)
+	Payload   any            // 파서가 반환한 값 (AttackData, HPData, ActionData 등)
+	Timestamp time.Time      // 세그먼트를 완성한 패킷의 캡처 시각
+	Net       gopacket.Flow  // 스트림의 네트워크 계층 흐름
+	Transport gopacket.Flow  // 스트림의 전송 계층 흐름
+	Direction Direction      // 게임 서버 기준 방향
+	Server    netip.AddrPort // 게임 서버 종단점
+
+	LocalPlayerID uint32 // 로컬 플레이어 ID. 아직 알 수 없으면 0
+}
+
+// StreamReset PCAP 재생을 처음부터 다시 시작했음을 알리는 이벤트 페이로드.
+// 이후 이벤트의 캡처 시각은 이전 이벤트보다 앞설 수 있으므로 구독자는 쌓아 둔 상태를 비워야 합니다.
+type StreamReset struct{}
+
+// streamResetName StreamReset 이벤트의 Name
+const streamResetName = "stream_reset"
+
+// Subscription 이벤트 구독. C에서 이벤트를 읽고, 더 이상 필요 없으면 Close를 호출해야 합니다.
+// 스니퍼가 종료되면 C가 닫힙니다.
+type Subscription struct {
+	C <-chan Event
+
+	ch       chan Event
+	done     chan struct{}
+	blocking bool
+	dropped  atomic.Uint64
+	stopOnce sync.Once
+	once     sync.Once
+}
+
+var (
+	subscribersMu sync.RWMutex
+	subscribers   = make(map[*Subscription]struct{})
+)
+
+// Subscribe 버퍼 크기가 buffer인 구독을 만듭니다.
+// 버퍼가 가득 차면 새 이벤트는 버려지고 Dropped 카운트가 증가합니다 (스니퍼를 막지 않음).
+func Subscribe(buffer int) *Subscription {
+	return subscribe(buffer, false)
+}
+
+// SubscribeBlocking 이벤트를 버리지 않는 구독을 만듭니다.
+// 버퍼가 가득 차면 스니퍼가 소비될 때까지 대기하므로, 소비 속도가 캡처 속도를 따라가야 합니다.
+// 더 이상 읽지 않을 때는 반드시 Close를 호출해야 대기 중인 스니퍼가 풀려납니다.
+func SubscribeBlocking(buffer int) *Subscription {
+	return subscribe(buffer, true)
+}
+
+func subscribe(buffer int, blocking bool) *Subscription {
+	if buffer < 0 {
+		buffer = 0
+	}
+
+	ch := make(chan Event, buffer)
+	s := &Subscription{
+		C:        ch,
+		ch:       ch,
+		done:     make(chan struct{}),
+		blocking: blocking,
+	}
+
+	subscribersMu.Lock()
+	subscribers[s] = struct{}{}
+	subscribersMu.Unlock()
+
+	return s
+}
+
+// Dropped 버퍼가 가득 차서 버려진 이벤트 수를 반환합니다
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 구독을 해지하고 C를 닫습니다. 여러 번 호출해도 안전합니다.
+func (s *Subscription) Close() {
+	// 대기 중인 publish를 먼저 깨운 뒤 잠금을 얻어야 교착 상태가 생기지 않습니다
+	s.stop()
+
+	s.once.Do(func() {
+		subscribersMu.Lock()
+		delete(subscribers, s)
+		subscribersMu.Unlock()
+
+		close(s.ch)
+	})
+}
+
+// stop 이 구독으로의 전달을 멈추고 대기 중인 deliver를 깨웁니다
+func (s *Subscription) stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// deliver 이벤트를 구독에 전달합니다.
+// 대기하는 구독은 Close(또는 closeSubscriptions)로 done이 닫힐 때까지만 기다리므로 스니퍼 초기화 여부와 관계없이 동작합니다.
+func (s *Subscription) deliver(event Event) {
+	if s.blocking {
+		select {
+		case s.ch <- event:
+		case <-s.done:
+		}
+		return
+	}
+
+	select {
+	case s.ch <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// publish 모든 구독자에게 이벤트를 전달합니다
+func publish(event Event) {
+	subscribersMu.RLock()
+	defer subscribersMu.RUnlock()
+
+	for s := range subscribers {
+		s.deliver(event)
+	}
+}
+
+// closeSubscriptions 모든 구독을 닫아 구독자에게 스트림 종료를 알립니다
+func closeSubscriptions() {
+	subscribersMu.RLock()
+	list := make([]*Subscription, 0, len(subscribers))
+	for s := range subscribers {
+		list = append(list, s)
+	}
+	subscribersMu.RUnlock()
+
+	// publish가 다른 구독에서 대기 중일 수 있으므로 모든 구독의 전달을 먼저 멈춘 뒤 닫습니다
+	for _, s := range list {
+		s.stop()
+	}
+
+	for _, s := range list {
+		s.Close()
+	}
+}
@@ -0,0 +1,84 @@
+package packet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribeDropsWhenFull(t *testing.T) {
+	sub := Subscribe(1)
+	defer sub.Close()
+
+	publish(Event{Type: 1})
+	publish(Event{Type: 2})
+
+	if dropped := sub.Dropped(); dropped != 1 {
+		t.Fatalf("Dropped() = %d, want 1", dropped)
+	}
+
+	if event := <-sub.C; event.Type != 1 {
+		t.Fatalf("received type %d, want 1", event.Type)
+	}
+}
+
+func TestBlockingSubscriptionCloseReleasesPublisher(t *testing.T) {
+	// 스니퍼를 초기화하지 않은 상태에서도 Close가 대기 중인 publish를 풀어야 합니다
+	sub := SubscribeBlocking(0)
+
+	published := make(chan struct{})
+	go func() {
+		publish(Event{Type: 1})
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("publish returned before the event was consumed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	sub.Close()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("publish still blocked after Close")
+	}
+
+	if _, ok := <-sub.C; ok {
+		t.Fatal("C is still open after Close")
+	}
+}
+
+func TestCloseSubscriptionsClosesChannels(t *testing.T) {
+	subs := []*Subscription{Subscribe(1), SubscribeBlocking(0)}
+
+	done := make(chan struct{})
+	go func() {
+		publish(Event{Type: 1})
+		close(done)
+	}()
+
+	// 대기하는 구독 때문에 막힌 publish도 closeSubscriptions로 풀려야 합니다
+	time.Sleep(20 * time.Millisecond)
+	closeSubscriptions()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish still blocked after closeSubscriptions")
+	}
+
+	for i, sub := range subs {
+		for range sub.C {
+		}
+		sub.Close() // 이미 닫힌 구독을 다시 닫아도 안전해야 합니다
+
+		subscribersMu.RLock()
+		_, ok := subscribers[sub]
+		subscribersMu.RUnlock()
+		if ok {
+			t.Fatalf("subscription %d is still registered", i)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package packet
+
+import (
+	"bytes"
+	"log"
+)
+
+// 스트림 하나가 보관할 수 있는 미완성 프레임 데이터의 최대 크기
+const maxFrameBufferLength = 1024 * 1024
+
+// 이미 처리된 앞부분이 이 크기 이상이고 남은 데이터보다 클 때만 버퍼를 새로 옮깁니다
+const minFrameCompactLength = 4 * 1024
+
+// frameDecoder 여러 번의 TCP 재조립 데이터에 걸친 프레임을 이어 붙여 분석하는 스트림 단위 디코더
+type frameDecoder struct {
+	buf       []byte
+	start     int // buf에서 아직 처리되지 않은 데이터의 시작 위치
+	maxBuffer int
+	profile   *Profile
+
+	resyncs      int
+	droppedBytes int
+}
+
+func newFrameDecoder(maxBuffer int, profile *Profile) *frameDecoder {
+	return &frameDecoder{
+		maxBuffer: maxBuffer,
+		profile:   profile,
+	}
+}
+
+// Decode 이전에 남은 미완성 데이터 뒤에 data를 이어 붙여 완결된 프레임들을 분석합니다.
+// 반환된 Content는 디코더 내부 버퍼를 참조하지만 이후 호출에서 덮어쓰이지 않습니다.
+func (d *frameDecoder) Decode(data []byte) []AnalyzedData {
+	if len(data) == 0 {
+		return nil
+	}
+
+	// data는 재조립기의 페이지 메모리일 수 있으므로 항상 복사해서 보관합니다.
+	// append는 기존 길이 뒤에만 쓰므로 이미 반환된 Content를 덮어쓰지 않습니다.
+	d.buf = append(d.buf, data...)
+
+	analyzed, consumed := analyzeFrames(d.buf[d.start:], d.profile)
+	d.skip(consumed)
+
+	if d.pending() > d.maxBuffer {
+		d.resync()
+	}
+
+	return analyzed
+}
+
+// Reset 보관 중인 미완성 데이터를 버립니다 (스트림에 누락 구간이 생긴 경우 등)
+func (d *frameDecoder) Reset() {
+	d.droppedBytes += d.pending()
+	d.buf = nil
+	d.start = 0
+}
+
+// pending 아직 처리되지 않은 데이터의 길이를 반환합니다
+func (d *frameDecoder) pending() int {
+	return len(d.buf) - d.start
+}
+
+// skip 처리되지 않은 데이터 중 앞의 n바이트를 처리된 것으로 표시합니다.
+// 처리된 앞부분이 충분히 커졌을 때만 남은 데이터를 새 버퍼로 옮기므로 복사 비용은 데이터 길이에 비례합니다.
+// 이미 반환된 Content가 참조하는 기존 버퍼는 재사용하지 않습니다.
+func (d *frameDecoder) skip(n int) {
+	d.start += n
+
+	switch {
+	case d.start >= len(d.buf):
+		d.buf = nil
+		d.start = 0
+	case d.start >= minFrameCompactLength && d.start >= d.pending():
+		d.buf = append([]byte(nil), d.buf[d.start:]...)
+		d.start = 0
+	}
+}
+
+// resync 버퍼가 한도를 넘으면 끝을 찾지 못한 프레임을 버리고 다음 프레임 시작 위치부터 다시 동기화합니다.
+// 남길 수 있는 시작 위치가 없으면 시작 구분자 일부일 수 있는 꼬리만 남기고 모두 버립니다.
+func (d *frameDecoder) resync() {
+	pending := d.buf[d.start:]
+	before := len(pending)
+	keepFrom := before - (len(d.profile.StartDelimiter) - 1)
+
+	if idx := bytes.LastIndex(pending, d.profile.StartDelimiter); idx > 0 && before-idx <= d.maxBuffer {
+		keepFrom = idx
+	}
+
+	d.skip(keepFrom)
+
+	d.resyncs++
+	d.droppedBytes += keepFrom
+	log.Printf("frame decoder buffer overflow: dropped %d bytes, kept %d bytes (resyncs: %d, total dropped: %d)", keepFrom, d.pending(), d.resyncs, d.droppedBytes)
+}
@@ -0,0 +1,163 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFrameDecoderSplitDelimiter(t *testing.T) {
+	profile := testProfile()
+	frame := testFrame(profile, testSegment(1, []byte("hello")))
+
+	tests := []struct {
+		name  string
+		split int
+	}{
+		{"inside start delimiter", 4},
+		{"after start delimiter", len(profile.StartDelimiter)},
+		{"inside segment metadata", len(profile.StartDelimiter) + 3},
+		{"inside end delimiter", len(frame) - 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := newFrameDecoder(maxFrameBufferLength, profile)
+
+			assertContents(t, decoder.Decode(frame[:tt.split]))
+			assertContents(t, decoder.Decode(frame[tt.split:]), "hello")
+
+			if pending := decoder.pending(); pending != 0 {
+				t.Fatalf("pending = %d bytes after a complete frame, want 0", pending)
+			}
+		})
+	}
+}
+
+func TestFrameDecoderGarbageBeforeStart(t *testing.T) {
+	profile := testProfile()
+	decoder := newFrameDecoder(maxFrameBufferLength, profile)
+
+	// 시작 구분자의 앞부분과 같은 바이트로 끝나는 쓰레기 데이터도 프레임으로 오인하지 않아야 합니다
+	garbage := append([]byte("garbage"), profile.StartDelimiter[:3]...)
+	data := append(garbage, testFrame(profile, testSegment(1, []byte("a")), testSegment(2, []byte("bc")))...)
+
+	assertContents(t, decoder.Decode(data), "a", "bc")
+}
+
+func TestFrameDecoderResetDropsPartialFrame(t *testing.T) {
+	profile := testProfile()
+	decoder := newFrameDecoder(maxFrameBufferLength, profile)
+
+	first := testFrame(profile, testSegment(1, []byte("lost")))
+	second := testFrame(profile, testSegment(1, []byte("kept")))
+
+	cut := len(first) - len(profile.EndDelimiter) - 2
+	assertContents(t, decoder.Decode(first[:cut]))
+
+	// 누락 구간 뒤의 나머지는 시작 구분자가 없으므로 앞 프레임과 이어 붙이지 않아야 합니다
+	decoder.Reset()
+	if decoder.droppedBytes != cut {
+		t.Fatalf("droppedBytes = %d, want %d", decoder.droppedBytes, cut)
+	}
+
+	assertContents(t, decoder.Decode(append(bytes.Clone(first[cut:]), second...)), "kept")
+}
+
+func TestFrameDecoderBufferCap(t *testing.T) {
+	profile := testProfile()
+	decoder := newFrameDecoder(maxFrameBufferLength, profile)
+
+	// 끝 구분자가 없는 프레임이 한도를 넘으면 버려지고 다음 프레임부터 다시 동기화됩니다
+	assertContents(t, decoder.Decode(profile.StartDelimiter))
+
+	chunk := bytes.Repeat([]byte{0xaa}, 64*1024)
+	for decoder.resyncs == 0 {
+		assertContents(t, decoder.Decode(chunk))
+	}
+
+	if pending := decoder.pending(); pending > maxFrameBufferLength {
+		t.Fatalf("pending = %d bytes after resync, want at most %d", pending, maxFrameBufferLength)
+	}
+
+	if decoder.droppedBytes <= maxFrameBufferLength-len(chunk) {
+		t.Fatalf("droppedBytes = %d, want the unterminated frame to be dropped", decoder.droppedBytes)
+	}
+
+	assertContents(t, decoder.Decode(testFrame(profile, testSegment(1, []byte("next")))), "next")
+}
+
+func TestFrameDecoderBufferCapKeepsLastStart(t *testing.T) {
+	profile := testProfile()
+	decoder := newFrameDecoder(64, profile)
+
+	// 한도를 넘긴 데이터 안에 새 프레임 시작이 있으면 그 위치부터 남겨야 합니다
+	frame := testFrame(profile, testSegment(1, []byte("tail")))
+	cut := len(profile.StartDelimiter) + 4
+
+	data := append(bytes.Clone(profile.StartDelimiter), bytes.Repeat([]byte{0xaa}, 80)...)
+	data = append(data, frame[:cut]...)
+
+	assertContents(t, decoder.Decode(data))
+	if decoder.resyncs != 1 {
+		t.Fatalf("resyncs = %d, want 1", decoder.resyncs)
+	}
+
+	assertContents(t, decoder.Decode(frame[cut:]), "tail")
+}
+
+func TestFrameDecoderContentStable(t *testing.T) {
+	profile := testProfile()
+	decoder := newFrameDecoder(maxFrameBufferLength, profile)
+
+	frame := testFrame(profile, testSegment(1, []byte("first")))
+	next := testFrame(profile, testSegment(1, []byte("other")))
+
+	// 미완성 꼬리를 남겨 내부 버퍼가 다음 호출에서 이어 쓰이도록 합니다
+	analyzed := decoder.Decode(append(bytes.Clone(frame), next[:5]...))
+	assertContents(t, analyzed, "first")
+
+	for range 100 {
+		decoder.Decode(next[5:])
+		decoder.Decode(next)
+		decoder.Decode(next[:5])
+	}
+
+	assertContents(t, analyzed, "first")
+}
+
+func TestFrameDecoderCompactsConsumedPrefix(t *testing.T) {
+	profile := testProfile()
+	decoder := newFrameDecoder(maxFrameBufferLength, profile)
+
+	frame := testFrame(profile, testSegment(1, bytes.Repeat([]byte{'x'}, 100)))
+	half := len(frame) / 2
+
+	// 매번 프레임 절반이 남는 스트림에서도 버퍼가 처리된 데이터만큼 계속 커지지 않아야 합니다
+	decoder.Decode(frame[:half])
+	for range 1000 {
+		assertContents(t, decoder.Decode(append(bytes.Clone(frame[half:]), frame[:half]...)), string(bytes.Repeat([]byte{'x'}, 100)))
+	}
+
+	if limit := minFrameCompactLength + 2*len(frame); len(decoder.buf) > limit {
+		t.Fatalf("buffer grew to %d bytes, want at most %d", len(decoder.buf), limit)
+	}
+
+	if pending := decoder.pending(); pending != half {
+		t.Fatalf("pending = %d, want %d", pending, half)
+	}
+}
+
+func BenchmarkFrameDecoderPartialFrames(b *testing.B) {
+	profile := testProfile()
+	decoder := newFrameDecoder(maxFrameBufferLength, profile)
+
+	frame := testFrame(profile, testSegment(1, bytes.Repeat([]byte{'x'}, 256)))
+	chunk := append(bytes.Clone(frame[len(frame)/2:]), frame[:len(frame)/2]...)
+	decoder.Decode(frame[:len(frame)/2])
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(chunk)))
+	for b.Loop() {
+		decoder.Decode(chunk)
+	}
+}
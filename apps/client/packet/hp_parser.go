@@ -1,39 +1,31 @@
 package packet
 
-import (
-	"encoding/binary"
-	"fmt"
-)
+import "fmt"
 
 type HPData struct {
-	TargetID uint32
-	Prev     uint32
-	Current  uint32
-	Damage   uint32
+	TargetID uint32 `layout:"u32,at=0"`
+	Prev     uint32 `layout:"u32,at=8"`
+	Current  uint32 `layout:"u32,at=16"`
+	Damage   uint32 `layout:"-"` // Prev - Current
 }
 
 const hpDataMinLength = 20
 
+var hpLayout = mustNewLayout[HPData]("hp")
+
 func parseHP(data []byte) (HPData, error) {
 	if len(data) < hpDataMinLength {
 		return HPData{}, fmt.Errorf("HP packet too short: got %d bytes, need at least %d bytes", len(data), hpDataMinLength)
 	}
 
-	targetID := binary.LittleEndian.Uint32(data[0:4])
-	// Skip bytes 4-7 (unused)
-	prev := binary.LittleEndian.Uint32(data[8:12])
-	// Skip bytes 12-15 (unused)
-	current := binary.LittleEndian.Uint32(data[16:20])
+	hp, err := hpLayout.Decode(data)
+	if err != nil {
+		return HPData{}, err
+	}
 
-	damage := uint32(0)
-	if prev > current {
-		damage = prev - current
+	if hp.Prev > hp.Current {
+		hp.Damage = hp.Prev - hp.Current
 	}
 
-	return HPData{
-		TargetID: targetID,
-		Prev:     prev,
-		Current:  current,
-		Damage:   damage,
-	}, nil
+	return hp, nil
 }
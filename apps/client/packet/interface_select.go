@@ -0,0 +1,205 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+)
+
+// InterfaceAuto 게임 트래픽이 보이는 인터페이스를 자동으로 찾습니다
+const InterfaceAuto = "auto"
+
+// 자동 선택 시 인터페이스별 패킷 읽기 타임아웃 (프로브 종료를 확인하는 주기)
+const probeReadTimeout = 200 * time.Millisecond
+
+var probeDuration = 3 * time.Second
+
+// SetInterfaceProbeDuration 자동 선택 시 각 인터페이스를 관찰할 시간을 설정합니다
+func SetInterfaceProbeDuration(duration time.Duration) {
+	probeDuration = duration
+}
+
+// interfaceProbe 인터페이스 하나를 관찰한 결과
+type interfaceProbe struct {
+	device  pcap.Interface
+	packets int // 필터에 맞는 TCP 패킷 수
+	matches int // 페이로드에 프로필의 구분자가 있는 패킷 수
+	err     error
+}
+
+// selectInterface spec(이름, IP 주소, auto 또는 빈 값)에 맞는 캡처 인터페이스와 선택 이유를 반환합니다
+func selectInterface(devices []pcap.Interface, spec string) (string, string, error) {
+	if spec == "" || spec == InterfaceAuto {
+		return probeInterfaces(devices)
+	}
+
+	for _, device := range devices {
+		if device.Name == spec {
+			return device.Name, "selected by name", nil
+		}
+	}
+
+	if addr, err := netip.ParseAddr(spec); err == nil {
+		for _, device := range devices {
+			for _, address := range device.Addresses {
+				if ip, ok := netip.AddrFromSlice(address.IP); ok && ip.Unmap() == addr.Unmap() {
+					return device.Name, fmt.Sprintf("has address %s", addr), nil
+				}
+			}
+		}
+		return "", "", fmt.Errorf("no network device has address %s (available: %s)", addr, describeDevices(devices))
+	}
+
+	return "", "", fmt.Errorf("network device not found: %s (available: %s)", spec, describeDevices(devices))
+}
+
+// probeInterfaces 모든 인터페이스를 동시에 관찰해 게임 트래픽이 가장 많은 인터페이스를 고릅니다.
+// 게임 트래픽이 보이지 않으면 주소가 있는 첫 번째 비루프백 인터페이스를 사용합니다.
+func probeInterfaces(devices []pcap.Interface) (string, string, error) {
+	profile := currentProfile()
+	filter := captureFilter()
+
+	probes := make([]interfaceProbe, len(devices))
+	var probeWG sync.WaitGroup
+	for i, device := range devices {
+		probeWG.Add(1)
+		go func() {
+			defer probeWG.Done()
+			probes[i] = probeInterface(device, filter, profile)
+		}()
+	}
+	probeWG.Wait()
+
+	for _, probe := range probes {
+		if probe.err != nil {
+			log.Printf("Interface probe skipped %s: %v", probe.device.Name, probe.err)
+		}
+	}
+
+	return rankProbes(probes, filter, probeDuration)
+}
+
+// rankProbes 구분자가 보인 패킷이 가장 많은 인터페이스를, 같으면 필터에 맞는 패킷이 가장 많은 인터페이스를 고릅니다.
+// 게임 트래픽이 보인 인터페이스가 없으면 주소가 있는 첫 번째 비루프백 인터페이스, 그것도 없으면 첫 번째 인터페이스를 고릅니다.
+func rankProbes(probes []interfaceProbe, filter string, duration time.Duration) (string, string, error) {
+	var best *interfaceProbe
+	for i := range probes {
+		probe := &probes[i]
+		if probe.err != nil {
+			continue
+		}
+
+		if best == nil || probe.matches > best.matches || (probe.matches == best.matches && probe.packets > best.packets) {
+			best = probe
+		}
+	}
+
+	if best != nil && best.matches > 0 {
+		return best.device.Name, fmt.Sprintf("%d of %d packets matched the protocol delimiters within %s", best.matches, best.packets, duration), nil
+	}
+
+	if best != nil && best.packets > 0 {
+		return best.device.Name, fmt.Sprintf("%d packets matched filter %q within %s but none carried the protocol delimiters", best.packets, filter, duration), nil
+	}
+
+	for _, probe := range probes {
+		if len(probe.device.Addresses) > 0 && !isLoopback(probe.device) {
+			return probe.device.Name, fmt.Sprintf("no game traffic within %s, first non-loopback device with an address", duration), nil
+		}
+	}
+
+	if len(probes) > 0 {
+		return probes[0].device.Name, fmt.Sprintf("no game traffic within %s, first device", duration), nil
+	}
+
+	return "", "", errors.New("no network devices found")
+}
+
+func probeInterface(device pcap.Interface, filter string, profile *Profile) interfaceProbe {
+	probe := interfaceProbe{device: device}
+
+	handle, err := pcap.OpenLive(device.Name, 65535, true, probeReadTimeout)
+	if err != nil {
+		probe.err = err
+		return probe
+	}
+	defer handle.Close()
+
+	if err := handle.SetBPFFilter(filter); err != nil {
+		probe.err = err
+		return probe
+	}
+
+	deadline := time.Now().Add(probeDuration)
+	for time.Now().Before(deadline) {
+		data, _, err := handle.ReadPacketData()
+		if errors.Is(err, pcap.NextErrorTimeoutExpired) {
+			continue
+		}
+		if err != nil {
+			probe.err = err
+			return probe
+		}
+
+		packet := gopacket.NewPacket(data, handle.LinkType(), gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+		tcp, ok := packet.Layer(layers.LayerTypeTCP).(*layers.TCP)
+		if !ok {
+			continue
+		}
+
+		probe.packets++
+		if carriesDelimiter(tcp.Payload, profile) {
+			probe.matches++
+		}
+	}
+
+	return probe
+}
+
+// carriesDelimiter 페이로드에 프로필의 시작 또는 끝 구분자가 있는지 확인합니다.
+// 적용된 프로필은 Validate를 거치므로 구분자가 비어 있지 않습니다.
+func carriesDelimiter(payload []byte, profile *Profile) bool {
+	for _, delimiter := range [][]byte{profile.StartDelimiter, profile.EndDelimiter} {
+		if bytes.Contains(payload, delimiter) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoopback(device pcap.Interface) bool {
+	for _, address := range device.Addresses {
+		if address.IP.IsLoopback() {
+			return true
+		}
+	}
+	return device.Name == "lo" || strings.HasPrefix(strings.ToLower(device.Description), "loopback")
+}
+
+// describeDevices 오류 메시지에 넣을 인터페이스 목록
+func describeDevices(devices []pcap.Interface) string {
+	names := make([]string, 0, len(devices))
+	for _, device := range devices {
+		var addrs []string
+		for _, address := range device.Addresses {
+			addrs = append(addrs, address.IP.String())
+		}
+
+		if len(addrs) == 0 {
+			names = append(names, device.Name)
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s [%s]", device.Name, strings.Join(addrs, " ")))
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,96 @@
+package packet
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/pcap"
+)
+
+func testDevice(name string, addrs ...string) pcap.Interface {
+	device := pcap.Interface{Name: name}
+	for _, addr := range addrs {
+		device.Addresses = append(device.Addresses, pcap.InterfaceAddress{IP: net.ParseIP(addr)})
+	}
+	return device
+}
+
+func TestRankProbes(t *testing.T) {
+	lo := testDevice("lo", "127.0.0.1")
+	down := testDevice("eth1")
+	eth := testDevice("eth0", "192.168.0.10")
+	wifi := testDevice("wlan0", "192.168.0.11")
+
+	tests := []struct {
+		name       string
+		probes     []interfaceProbe
+		want       string
+		wantReason string
+	}{
+		{
+			name:       "most delimiter matches wins over more packets",
+			probes:     []interfaceProbe{{device: eth, packets: 100, matches: 2}, {device: wifi, packets: 10, matches: 5}},
+			want:       "wlan0",
+			wantReason: "5 of 10 packets matched the protocol delimiters",
+		},
+		{
+			name:       "equal matches fall back to packets",
+			probes:     []interfaceProbe{{device: eth, packets: 10, matches: 3}, {device: wifi, packets: 20, matches: 3}},
+			want:       "wlan0",
+			wantReason: "3 of 20 packets",
+		},
+		{
+			name:       "filtered packets without delimiters",
+			probes:     []interfaceProbe{{device: lo}, {device: eth, packets: 4}},
+			want:       "eth0",
+			wantReason: "none carried the protocol delimiters",
+		},
+		{
+			name:       "failed probe is never selected",
+			probes:     []interfaceProbe{{device: eth, packets: 50, matches: 50, err: errors.New("permission denied")}, {device: wifi, packets: 1, matches: 1}},
+			want:       "wlan0",
+			wantReason: "1 of 1 packets",
+		},
+		{
+			name:       "no traffic picks the first non-loopback device with an address",
+			probes:     []interfaceProbe{{device: lo}, {device: down}, {device: eth}, {device: wifi}},
+			want:       "eth0",
+			wantReason: "first non-loopback device with an address",
+		},
+		{
+			name:       "no addresses picks the first device",
+			probes:     []interfaceProbe{{device: lo}, {device: down, err: errors.New("no such device")}},
+			want:       "lo",
+			wantReason: "first device",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason, err := rankProbes(tt.probes, "tcp port 1234", time.Second)
+			if err != nil {
+				t.Fatalf("rankProbes: %v", err)
+			}
+			if got != tt.want || !strings.Contains(reason, tt.wantReason) {
+				t.Fatalf("rankProbes = %s (%s), want %s (%s)", got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+
+	if _, _, err := rankProbes(nil, "", time.Second); err == nil {
+		t.Fatal("rankProbes accepted no devices")
+	}
+}
+
+func TestCarriesDelimiter(t *testing.T) {
+	profile := &Profile{StartDelimiter: HexBytes{0x68, 0x27}, EndDelimiter: HexBytes{0xe3, 0x27}}
+	if !carriesDelimiter([]byte{0x00, 0x68, 0x27, 0x01}, profile) || !carriesDelimiter([]byte{0xe3, 0x27}, profile) {
+		t.Fatal("delimiters not found")
+	}
+	if carriesDelimiter([]byte{0x68, 0x00, 0x27}, profile) {
+		t.Fatal("split delimiter matched")
+	}
+}
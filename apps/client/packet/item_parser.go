@@ -0,0 +1,46 @@
+package packet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// ItemData 아이템 획득 정보 (타입 100321, 100322).
+// 필드 위치는 캡처로 확인되지 않았으므로 기본 프로필에는 등록하지 않습니다. 그동안 두 타입은 미확인 타입 카탈로그에 샘플로 남습니다.
+// 확인한 뒤 프로토콜 파일의 types에 "item": [100321, 100322]를 추가하면 사용할 수 있습니다.
+type ItemData struct {
+	OwnerID  uint32 `layout:"u32,at=0"`
+	ItemID   uint32 `layout:"u32,at=8"`
+	Quantity uint32 `layout:"u32,at=12"`
+	Rarity   uint8  `layout:"u8,at=16"`
+	Bound    bool   `layout:"bit,at=17,mask=1"` // 귀속 여부
+	Name     string `layout:"utf16str32,at=18"`
+}
+
+const itemDataMinLength = 22
+
+var itemLayout = mustNewLayout[ItemData]("item")
+
+func parseItem(data []byte) (ItemData, error) {
+	if len(data) < itemDataMinLength {
+		return ItemData{}, fmt.Errorf("item packet too short: %d bytes, need at least %d", len(data), itemDataMinLength)
+	}
+
+	return itemLayout.Decode(data)
+}
+
+// decodeUTF16LE UTF-16LE 문자열(한글 포함)을 디코딩합니다. 널 종료 문자 이후는 무시합니다.
+func decodeUTF16LE(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i : i+2])
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+
+	return strings.TrimSpace(string(utf16.Decode(units)))
+}
@@ -0,0 +1,108 @@
+package packet
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// itemFixture ItemData가 가정한 배치대로 아이템 획득 세그먼트를 구성합니다. 실제 캡처가 아니므로 파서가 구현한 배치만 고정합니다.
+// nameLength가 음수면 name의 실제 바이트 길이를 씁니다.
+func itemFixture(name string, nameLength int) []byte {
+	data := []byte{
+		0x01, 0x02, 0x03, 0x04, // 0: OwnerID
+		0xde, 0xad, 0xbe, 0xef,
+		0x10, 0x27, 0x00, 0x00, // 8: ItemID (10000)
+		0x03, 0x00, 0x00, 0x00, // 12: Quantity
+		0x04, // 16: Rarity
+		0x01, // 17: 귀속 플래그
+	}
+
+	var encoded []byte
+	for _, unit := range utf16.Encode([]rune(name)) {
+		encoded = binary.LittleEndian.AppendUint16(encoded, unit)
+	}
+
+	if nameLength < 0 {
+		nameLength = len(encoded)
+	}
+
+	data = binary.LittleEndian.AppendUint32(data, uint32(nameLength))
+	return append(data, encoded...)
+}
+
+func TestParseItem(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want ItemData
+	}{
+		{
+			name: "korean name",
+			data: itemFixture("빛나는 검", -1),
+			want: ItemData{OwnerID: 0x04030201, ItemID: 10000, Quantity: 3, Rarity: 4, Bound: true, Name: "빛나는 검"},
+		},
+		{
+			name: "empty name",
+			data: itemFixture("", -1),
+			want: ItemData{OwnerID: 0x04030201, ItemID: 10000, Quantity: 3, Rarity: 4, Bound: true},
+		},
+		{
+			name: "null terminated name",
+			data: itemFixture("potion\x00junk", -1),
+			want: ItemData{OwnerID: 0x04030201, ItemID: 10000, Quantity: 3, Rarity: 4, Bound: true, Name: "potion"},
+		},
+		{
+			name: "shorter name length than payload",
+			data: itemFixture("potion", 6),
+			want: ItemData{OwnerID: 0x04030201, ItemID: 10000, Quantity: 3, Rarity: 4, Bound: true, Name: "pot"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseItem(tt.data)
+			if err != nil {
+				t.Fatalf("parseItem: %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("parseItem = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseItemRejectsBadNameLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"odd length", itemFixture("potion", 5), "is odd"},
+		{"one byte past the end", itemFixture("potion", 13), "exceeds segment size"},
+		{"oversized", itemFixture("potion", 1<<20), "exceeds segment size"},
+		{"max uint32", itemFixture("potion", 0xffffffff), "exceeds segment size"},
+		{"too short", itemFixture("", -1)[:itemDataMinLength-1], "too short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseItem(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseItem error = %v, want %q", err, tt.wantErr)
+			}
+
+			if got != (ItemData{}) {
+				t.Fatalf("parseItem returned %+v with an error, want zero value", got)
+			}
+		})
+	}
+}
+
+func TestItemNotInDefaultProfile(t *testing.T) {
+	if types, ok := DefaultProfile().Types["item"]; ok {
+		t.Fatalf("default profile registers unverified item types %v", types)
+	}
+}
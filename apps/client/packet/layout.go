@@ -0,0 +1,329 @@
+package packet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 세그먼트 레이아웃은 구조체 필드의 `layout` 태그로 선언합니다.
+//
+//	layout:"<kind>[,at=<offset>][,skip=<n>][,size=<n>][,mask=<n>][,set=<name>]"
+//
+// kind:
+//   - u8, u16, u32, u64: 리틀 엔디언 부호 없는 정수 (uint 계열 필드)
+//   - bit: 1바이트를 읽어 mask 비트가 켜져 있는지 (bool 필드)
+//   - str32: u32 바이트 길이 접두 문자열, 널 바이트 제거 후 공백 정리 (string 필드)
+//   - utf16str32: u32 바이트 길이 접두 UTF-16LE 문자열, 길이가 홀수면 오류 (string 필드)
+//   - flags: size 바이트를 set 이름의 플래그 정의로 해석해 비트셋으로 저장 (uint 계열 필드)
+//
+// at은 세그먼트 시작 기준 절대 오프셋이고, 생략하면 직전 필드의 끝에서 skip 바이트 뒤를 읽습니다.
+// 태그가 없거나 "-"인 필드는 파싱 후 계산하는 값으로 보고 건너뜁니다.
+
+type layoutKind int
+
+const (
+	layoutUint layoutKind = iota
+	layoutBit
+	layoutStr32
+	layoutUTF16Str32
+	layoutFlags
+)
+
+type layoutField struct {
+	index int
+	name  string
+	kind  layoutKind
+	at    int // 절대 오프셋, 상대 위치면 -1
+	skip  int
+	size  int
+	mask  byte
+	flags []flagDef
+}
+
+// Layout 구조체 태그로 선언된 세그먼트 레이아웃과 그 해석기
+type Layout[T any] struct {
+	name   string
+	fields []layoutField
+}
+
+// flagDef 플래그 바이트 index의 mask 비트가 비트셋의 bit 위치에 대응함을 나타냅니다
+type flagDef struct {
+	index uint8
+	bit   DamageFlag
+	mask  byte
+}
+
+// flags 필드에서 set=<name>으로 참조할 수 있는 플래그 정의
+var flagSets = map[string][]flagDef{
+	"damage": damageFlagDefs,
+}
+
+// NewLayout T의 layout 태그를 해석해 레이아웃을 만듭니다
+func NewLayout[T any](name string) (*Layout[T], error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("layout %s: %s is not a struct", name, typ)
+	}
+
+	layout := &Layout[T]{name: name}
+
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+
+		tag, ok := structField.Tag.Lookup("layout")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		field, err := parseLayoutTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("layout %s: field %s: %w", name, structField.Name, err)
+		}
+
+		field.index = i
+		field.name = structField.Name
+
+		if err := field.checkType(structField.Type); err != nil {
+			return nil, fmt.Errorf("layout %s: field %s: %w", name, structField.Name, err)
+		}
+
+		layout.fields = append(layout.fields, field)
+	}
+
+	return layout, nil
+}
+
+// mustNewLayout 패키지 내부의 고정 레이아웃용. 태그 오류는 프로그래밍 오류이므로 초기화 시점에 바로 드러나게 합니다.
+func mustNewLayout[T any](name string) *Layout[T] {
+	layout, err := NewLayout[T](name)
+	if err != nil {
+		panic(err)
+	}
+
+	return layout
+}
+
+// Decode data를 레이아웃에 따라 해석합니다
+func (l *Layout[T]) Decode(data []byte) (T, error) {
+	var out T
+	if err := l.DecodeInto(data, &out); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return out, nil
+}
+
+// DecodeInto data를 레이아웃에 따라 해석해 out에 채웁니다
+func (l *Layout[T]) DecodeInto(data []byte, out *T) error {
+	value := reflect.ValueOf(out).Elem()
+	cursor := 0
+
+	for i := range l.fields {
+		field := &l.fields[i]
+
+		offset := cursor + field.skip
+		if field.at >= 0 {
+			offset = field.at
+		}
+
+		consumed, err := field.decode(data, offset, value.Field(field.index))
+		if err != nil {
+			return fmt.Errorf("%s.%s at offset %d: %w", l.name, field.name, offset, err)
+		}
+
+		cursor = offset + consumed
+	}
+
+	return nil
+}
+
+func parseLayoutTag(tag string) (layoutField, error) {
+	parts := strings.Split(tag, ",")
+	field := layoutField{at: -1}
+
+	switch kind := parts[0]; kind {
+	case "u8":
+		field.kind, field.size = layoutUint, 1
+	case "u16":
+		field.kind, field.size = layoutUint, 2
+	case "u32":
+		field.kind, field.size = layoutUint, 4
+	case "u64":
+		field.kind, field.size = layoutUint, 8
+	case "bit":
+		field.kind, field.size = layoutBit, 1
+	case "str32":
+		field.kind = layoutStr32
+	case "utf16str32":
+		field.kind = layoutUTF16Str32
+	case "flags":
+		field.kind = layoutFlags
+	default:
+		return field, fmt.Errorf("unknown kind %q", kind)
+	}
+
+	for _, option := range parts[1:] {
+		key, raw, ok := strings.Cut(option, "=")
+		if !ok {
+			return field, fmt.Errorf("invalid option %q", option)
+		}
+
+		if key == "set" {
+			defs, ok := flagSets[raw]
+			if !ok {
+				return field, fmt.Errorf("unknown flag set %q", raw)
+			}
+			field.flags = defs
+			continue
+		}
+
+		n, err := strconv.ParseUint(raw, 0, 32)
+		if err != nil {
+			return field, fmt.Errorf("invalid option %q: %w", option, err)
+		}
+
+		switch key {
+		case "at":
+			field.at = int(n)
+		case "skip":
+			field.skip = int(n)
+		case "size":
+			if field.kind != layoutFlags {
+				return field, fmt.Errorf("size option is only valid for flags")
+			}
+			field.size = int(n)
+		case "mask":
+			if n == 0 || n > 0xff {
+				return field, fmt.Errorf("invalid mask %q", raw)
+			}
+			field.mask = byte(n)
+		default:
+			return field, fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	switch {
+	case field.kind == layoutBit && field.mask == 0:
+		return field, fmt.Errorf("bit field requires mask")
+	case field.kind == layoutFlags && (field.flags == nil || field.size == 0):
+		return field, fmt.Errorf("flags field requires set and size")
+	}
+
+	return field, nil
+}
+
+func (f *layoutField) checkType(typ reflect.Type) error {
+	var ok bool
+
+	switch f.kind {
+	case layoutUint:
+		switch typ.Kind() {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			ok = typ.Size() >= uintptr(f.size)
+		}
+	case layoutBit:
+		ok = typ.Kind() == reflect.Bool
+	case layoutStr32, layoutUTF16Str32:
+		ok = typ.Kind() == reflect.String
+	case layoutFlags:
+		switch typ.Kind() {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			ok = true
+			for _, def := range f.flags {
+				ok = ok && uintptr(def.bit) < typ.Size()*8
+			}
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("type %s does not match layout kind", typ)
+	}
+
+	return nil
+}
+
+// decode offset 위치의 값을 읽어 dst에 저장하고, 소비한 바이트 수를 반환합니다
+func (f *layoutField) decode(data []byte, offset int, dst reflect.Value) (int, error) {
+	switch f.kind {
+	case layoutUint:
+		if err := checkBounds(data, offset, f.size); err != nil {
+			return 0, err
+		}
+		dst.SetUint(readUint(data[offset:], f.size))
+		return f.size, nil
+
+	case layoutBit:
+		if err := checkBounds(data, offset, 1); err != nil {
+			return 0, err
+		}
+		dst.SetBool(data[offset]&f.mask != 0)
+		return 1, nil
+
+	case layoutStr32, layoutUTF16Str32:
+		if err := checkBounds(data, offset, 4); err != nil {
+			return 0, err
+		}
+		length := binary.LittleEndian.Uint32(data[offset : offset+4])
+		if uint64(length) > uint64(len(data)-offset-4) {
+			return 0, fmt.Errorf("string length %d exceeds segment size %d", length, len(data))
+		}
+		if f.kind == layoutUTF16Str32 && length%2 != 0 {
+			return 0, fmt.Errorf("UTF-16 string length %d is odd", length)
+		}
+
+		raw := data[offset+4 : offset+4+int(length)]
+		if f.kind == layoutUTF16Str32 {
+			dst.SetString(decodeUTF16LE(raw))
+		} else {
+			// removeNullBytes는 입력을 덮어쓰므로 원본 세그먼트를 보존하기 위해 복사본을 넘깁니다
+			dst.SetString(strings.TrimSpace(string(removeNullBytes(append([]byte(nil), raw...)))))
+		}
+		return 4 + int(length), nil
+
+	case layoutFlags:
+		if err := checkBounds(data, offset, f.size); err != nil {
+			return 0, err
+		}
+		dst.SetUint(decodeFlags(data[offset:offset+f.size], f.flags))
+		return f.size, nil
+	}
+
+	return 0, fmt.Errorf("unsupported layout kind %d", f.kind)
+}
+
+func checkBounds(data []byte, offset, size int) error {
+	if offset < 0 || offset+size > len(data) {
+		return fmt.Errorf("need %d bytes, segment has %d", offset+size, len(data))
+	}
+
+	return nil
+}
+
+func readUint(data []byte, size int) uint64 {
+	switch size {
+	case 1:
+		return uint64(data[0])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(data))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(data))
+	default:
+		return binary.LittleEndian.Uint64(data)
+	}
+}
+
+// decodeFlags 플래그 정의에 따라 플래그 바이트를 비트셋으로 해석합니다
+func decodeFlags(flagData []byte, defs []flagDef) uint64 {
+	var flags uint64
+	for _, def := range defs {
+		if def.index < uint8(len(flagData)) && flagData[def.index]&def.mask != 0 {
+			flags |= 1 << def.bit
+		}
+	}
+
+	return flags
+}
@@ -0,0 +1,550 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 아래 legacy* 함수들은 레이아웃 도입 전(user-009 이전)의 수작업 파서를 그대로 옮긴 참조 구현입니다.
+// 같은 입력에 대해 레이아웃 기반 파서와 결과가 같아야 합니다.
+
+var legacyDamageFlagDefs = []struct {
+	index uint8
+	name  string
+	mask  byte
+}{
+	{0, "crit", 1},
+	{0, "what1", 2},
+	{0, "unguarded", 4},
+	{0, "break", 8},
+	{0, "what05", 16},
+	{0, "what06", 32},
+	{0, "first_hit", 64},
+	{0, "default_attack", 128},
+
+	{1, "multi_attack", 1},
+	{1, "power", 2},
+	{1, "fast", 4},
+	{1, "dot1", 8},
+	{1, "dot2", 128},
+
+	{2, "dot3", 1},
+
+	{3, "add_hit", 8},
+	{3, "bleed", 16},
+	{3, "dark", 32},
+	{3, "fire", 64},
+	{3, "holy", 128},
+
+	{4, "ice", 1},
+	{4, "electric", 2},
+	{4, "poison", 4},
+	{4, "mind", 8},
+	{4, "dot4", 16},
+}
+
+func legacyParseDamageFlags(flagData []byte) map[string]bool {
+	flags := make(map[string]bool, len(legacyDamageFlagDefs))
+	for _, def := range legacyDamageFlagDefs {
+		flags[def.name] = false
+
+		if def.index < uint8(len(flagData)) {
+			flags[def.name] = (flagData[def.index] & def.mask) != 0
+		}
+	}
+
+	return flags
+}
+
+type legacyAttack struct {
+	UserID, TargetID, Key1, Key2 uint32
+	Flags                        map[string]bool
+}
+
+func legacyParseAttack(data []byte) legacyAttack {
+	return legacyAttack{
+		UserID:   binary.LittleEndian.Uint32(data[0:4]),
+		TargetID: binary.LittleEndian.Uint32(data[8:12]),
+		Key1:     binary.LittleEndian.Uint32(data[16:20]),
+		Key2:     binary.LittleEndian.Uint32(data[20:24]),
+		Flags:    legacyParseDamageFlags(data[24:31]),
+	}
+}
+
+func legacyParseHP(data []byte) HPData {
+	prev := binary.LittleEndian.Uint32(data[8:12])
+	current := binary.LittleEndian.Uint32(data[16:20])
+
+	damage := uint32(0)
+	if prev > current {
+		damage = prev - current
+	}
+
+	return HPData{
+		TargetID: binary.LittleEndian.Uint32(data[0:4]),
+		Prev:     prev,
+		Current:  current,
+		Damage:   damage,
+	}
+}
+
+func legacyParseAction(data []byte) ActionData {
+	// 예전 파서는 입력을 직접 덮어썼으므로 복사본을 넘깁니다
+	data = bytes.Clone(data)
+
+	skillNameLen := binary.LittleEndian.Uint32(data[8:12])
+	skillNameBytes := removeNullBytes(data[12 : 12+skillNameLen])
+
+	return ActionData{
+		UserID:    binary.LittleEndian.Uint32(data[0:4]),
+		SkillName: strings.TrimSpace(string(skillNameBytes)),
+		Key1:      binary.LittleEndian.Uint32(data[12+skillNameLen+8 : 12+skillNameLen+12]),
+	}
+}
+
+type legacySelfDamage struct {
+	AttackerID, VictimID, Damage uint32
+	Flags                        map[string]bool
+}
+
+func legacyParseSelfDamage(data []byte) legacySelfDamage {
+	return legacySelfDamage{
+		VictimID:   binary.LittleEndian.Uint32(data[0:4]),
+		AttackerID: binary.LittleEndian.Uint32(data[8:12]),
+		Damage:     binary.LittleEndian.Uint32(data[16:20]),
+		Flags:      legacyParseDamageFlags(data[24:31]),
+	}
+}
+
+func legacyParseItem(data []byte) (ItemData, error) {
+	nameLen := binary.LittleEndian.Uint32(data[18:22])
+	if uint64(nameLen) > uint64(len(data)-itemDataMinLength) {
+		return ItemData{}, fmt.Errorf("item name length %d exceeds packet size %d", nameLen, len(data))
+	}
+
+	return ItemData{
+		OwnerID:  binary.LittleEndian.Uint32(data[0:4]),
+		ItemID:   binary.LittleEndian.Uint32(data[8:12]),
+		Quantity: binary.LittleEndian.Uint32(data[12:16]),
+		Rarity:   data[16],
+		Bound:    data[17]&1 != 0,
+		Name:     decodeUTF16LE(data[itemDataMinLength : itemDataMinLength+int(nameLen)]),
+	}, nil
+}
+
+// assertLegacyFlags 비트셋이 예전 map[string]bool 플래그와 같은지 확인합니다
+func assertLegacyFlags(t *testing.T, got DamageFlags, want map[string]bool) {
+	t.Helper()
+
+	if len(want) != int(damageFlagCount) {
+		t.Fatalf("legacy parser produced %d flags, bitset has %d", len(want), damageFlagCount)
+	}
+
+	for name, set := range want {
+		flag, ok := ParseDamageFlag(name)
+		if !ok {
+			t.Fatalf("legacy flag %q has no DamageFlag", name)
+		}
+
+		if got.Has(flag) != set {
+			t.Fatalf("flag %s = %v, legacy parser has %v (flags %s)", name, got.Has(flag), set, got)
+		}
+	}
+}
+
+func randomBytes(rng *rand.Rand, n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(rng.UintN(256))
+	}
+
+	return data
+}
+
+// layoutFixtures 고정 입력과 무작위 입력을 섞은 고정 시드 픽스처
+func layoutFixtures(n int, fixed [][]byte, build func(rng *rand.Rand) []byte) [][]byte {
+	rng := rand.New(rand.NewPCG(9, 9))
+
+	fixtures := slices.Clone(fixed)
+	for range n {
+		fixtures = append(fixtures, build(rng))
+	}
+
+	return fixtures
+}
+
+func actionFixture(rng *rand.Rand, name []byte, trailing int) []byte {
+	data := randomBytes(rng, 8)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(name)))
+	data = append(data, name...)
+	data = append(data, randomBytes(rng, 12+trailing)...)
+	return data
+}
+
+func TestLayoutMatchesLegacyAttack(t *testing.T) {
+	fixtures := layoutFixtures(200, [][]byte{
+		make([]byte, attackDataLength),
+		bytes.Repeat([]byte{0xff}, attackDataLength),
+	}, func(rng *rand.Rand) []byte {
+		return randomBytes(rng, attackDataLength)
+	})
+
+	for i, data := range fixtures {
+		got, err := parseAttack(data)
+		if err != nil {
+			t.Fatalf("fixture %d: parseAttack: %v", i, err)
+		}
+
+		want := legacyParseAttack(data)
+		if got.UserID != want.UserID || got.TargetID != want.TargetID || got.Key1 != want.Key1 || got.Key2 != want.Key2 {
+			t.Fatalf("fixture %d: parseAttack = %+v, legacy %+v", i, got, want)
+		}
+		assertLegacyFlags(t, got.Flags, want.Flags)
+	}
+}
+
+func TestLayoutMatchesLegacyHP(t *testing.T) {
+	fixtures := layoutFixtures(200, [][]byte{
+		make([]byte, hpDataMinLength),
+		bytes.Repeat([]byte{0xff}, hpDataMinLength+4),
+	}, func(rng *rand.Rand) []byte {
+		return randomBytes(rng, hpDataMinLength+rng.IntN(16))
+	})
+
+	for i, data := range fixtures {
+		got, err := parseHP(data)
+		if err != nil {
+			t.Fatalf("fixture %d: parseHP: %v", i, err)
+		}
+
+		if want := legacyParseHP(data); got != want {
+			t.Fatalf("fixture %d: parseHP = %+v, legacy %+v", i, got, want)
+		}
+	}
+}
+
+func TestLayoutMatchesLegacyAction(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	fixtures := layoutFixtures(200, [][]byte{
+		actionFixture(rng, nil, 0),
+		actionFixture(rng, []byte("  Fire Ball\x00\x00 "), 0),
+		actionFixture(rng, []byte("\x00S\x00l\x00a\x00s\x00h"), 5),
+	}, func(rng *rand.Rand) []byte {
+		return actionFixture(rng, randomBytes(rng, rng.IntN(40)), rng.IntN(8))
+	})
+
+	for i, data := range fixtures {
+		original := bytes.Clone(data)
+
+		got, err := parseAction(data)
+		if err != nil {
+			t.Fatalf("fixture %d: parseAction: %v", i, err)
+		}
+
+		if want := legacyParseAction(data); got != want {
+			t.Fatalf("fixture %d: parseAction = %+v, legacy %+v", i, got, want)
+		}
+
+		// 레이아웃 파서는 원본 세그먼트를 바꾸지 않아야 합니다
+		if !bytes.Equal(data, original) {
+			t.Fatalf("fixture %d: parseAction modified its input", i)
+		}
+	}
+}
+
+func TestLayoutMatchesLegacySelfDamage(t *testing.T) {
+	fixtures := layoutFixtures(200, [][]byte{selfDamageFixture}, func(rng *rand.Rand) []byte {
+		return randomBytes(rng, selfDamageDataMinLength+rng.IntN(16))
+	})
+
+	for i, data := range fixtures {
+		got, err := parseSelfDamage(data)
+		if err != nil {
+			t.Fatalf("fixture %d: parseSelfDamage: %v", i, err)
+		}
+
+		want := legacyParseSelfDamage(data)
+		if got.AttackerID != want.AttackerID || got.VictimID != want.VictimID || got.Damage != want.Damage {
+			t.Fatalf("fixture %d: parseSelfDamage = %+v, legacy %+v", i, got, want)
+		}
+		assertLegacyFlags(t, got.Flags, want.Flags)
+	}
+}
+
+func TestLayoutMatchesLegacyItem(t *testing.T) {
+	fixtures := layoutFixtures(200, [][]byte{
+		itemFixture("", -1),
+		itemFixture("빛나는 검", -1),
+		itemFixture("potion", 1<<20),
+	}, func(rng *rand.Rand) []byte {
+		// UTF-16 이름 길이는 짝수여야 합니다 (홀수 길이는 레이아웃 파서만 거부합니다)
+		name := randomBytes(rng, 2*rng.IntN(16))
+		data := randomBytes(rng, 18)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(name)))
+		return append(data, append(name, randomBytes(rng, rng.IntN(4))...)...)
+	})
+
+	for i, data := range fixtures {
+		got, err := parseItem(data)
+		want, wantErr := legacyParseItem(data)
+
+		if (err != nil) != (wantErr != nil) {
+			t.Fatalf("fixture %d: parseItem error = %v, legacy error = %v", i, err, wantErr)
+		}
+
+		if got != want {
+			t.Fatalf("fixture %d: parseItem = %+v, legacy %+v", i, got, want)
+		}
+	}
+}
+
+// TestBuiltinParsersRejectTruncatedInput 모든 내장 파서가 잘린 입력에서 패닉 없이 오류를 반환하는지 확인합니다
+func TestBuiltinParsersRejectTruncatedInput(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	valid := map[string][]byte{
+		"attack":      randomBytes(rng, attackDataLength),
+		"hp":          randomBytes(rng, hpDataMinLength),
+		"action":      actionFixture(rng, []byte("Fire Ball"), 0),
+		"self_damage": selfDamageFixture,
+		"item":        itemFixture("빛나는 검", -1),
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(builtinParsers)) {
+		data, ok := valid[name]
+		if !ok {
+			t.Fatalf("no fixture for builtin parser %s", name)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			parser := builtinParsers[name]
+			if _, err := parser.Parse(data); err != nil {
+				t.Fatalf("valid fixture rejected: %v", err)
+			}
+
+			for length := range len(data) {
+				if _, err := parser.Parse(data[:length]); err == nil {
+					t.Fatalf("%d of %d bytes accepted", length, len(data))
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutDecodeOutOfRange(t *testing.T) {
+	// 세그먼트 최소 길이 확인을 거치지 않고 레이아웃을 직접 사용해도 패닉 없이 오류를 반환해야 합니다
+	tests := []struct {
+		name    string
+		decode  func([]byte) error
+		data    []byte
+		wantErr string
+	}{
+		{"empty attack", decodeErr(attackLayout), nil, "attack.UserID at offset 0"},
+		{"attack missing flags", decodeErr(attackLayout), make([]byte, 30), "attack.Flags at offset 24"},
+		{"action name past end", decodeErr(actionLayout), actionFixture(rand.New(rand.NewPCG(5, 6)), []byte("Slash"), 0)[:14], "exceeds segment size"},
+		{"action missing key", decodeErr(actionLayout), actionFixture(rand.New(rand.NewPCG(5, 6)), []byte("Slash"), 0)[:24], "action.Key1 at offset 25"},
+		{"item missing name length", decodeErr(itemLayout), make([]byte, 20), "item.Name at offset 18"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decode(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Decode error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func decodeErr[T any](layout *Layout[T]) func([]byte) error {
+	return func(data []byte) error {
+		_, err := layout.Decode(data)
+		return err
+	}
+}
+
+var layoutKindNames = map[layoutKind]string{
+	layoutUint:       "u",
+	layoutBit:        "bit",
+	layoutStr32:      "str32",
+	layoutUTF16Str32: "utf16str32",
+	layoutFlags:      "flags",
+}
+
+// describeLayout 레이아웃 필드 배치를 "필드 종류@위치" 목록으로 나타냅니다
+func describeLayout(fields []layoutField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		kind := layoutKindNames[field.kind]
+		switch field.kind {
+		case layoutUint:
+			kind += fmt.Sprint(field.size * 8)
+		case layoutBit:
+			kind += fmt.Sprintf("&%#x", field.mask)
+		case layoutFlags:
+			kind += fmt.Sprintf("[%d]", field.size)
+		}
+
+		at := fmt.Sprintf("@+%d", field.skip)
+		if field.at >= 0 {
+			at = fmt.Sprintf("@%d", field.at)
+		}
+
+		parts = append(parts, field.name+" "+kind+at)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// TestBuiltinLayouts 내장 레이아웃의 필드 배치를 고정합니다.
+// 태그를 바꾸면 이 테스트도 함께 바꿔야 하며, 새 내장 파서를 추가하면 여기에 레이아웃을 추가해야 합니다.
+func TestBuiltinLayouts(t *testing.T) {
+	layouts := map[string]string{
+		"attack":      describeLayout(attackLayout.fields),
+		"hp":          describeLayout(hpLayout.fields),
+		"action":      describeLayout(actionLayout.fields),
+		"self_damage": describeLayout(selfDamageLayout.fields),
+		"item":        describeLayout(itemLayout.fields),
+	}
+
+	want := map[string]string{
+		"attack":      "UserID u32@0, TargetID u32@8, Key1 u32@16, Key2 u32@20, Flags flags[7]@24",
+		"hp":          "TargetID u32@0, Prev u32@8, Current u32@16",
+		"action":      "UserID u32@0, SkillName str32@8, Key1 u32@+8",
+		"self_damage": "AttackerID u32@8, VictimID u32@0, Damage u32@16, Flags flags[7]@24",
+		"item":        "OwnerID u32@0, ItemID u32@8, Quantity u32@12, Rarity u8@16, Bound bit&0x1@17, Name utf16str32@18",
+	}
+
+	if got, wantNames := slices.Sorted(maps.Keys(layouts)), slices.Sorted(maps.Keys(builtinParsers)); !slices.Equal(got, wantNames) {
+		t.Fatalf("pinned layouts %v, builtin parsers %v", got, wantNames)
+	}
+
+	for name, got := range layouts {
+		if got != want[name] {
+			t.Errorf("%s layout:\n got %s\nwant %s", name, got, want[name])
+		}
+	}
+
+	for _, layout := range []struct {
+		name  string
+		flags []flagDef
+	}{
+		{"attack", attackLayout.fields[4].flags},
+		{"self_damage", selfDamageLayout.fields[3].flags},
+	} {
+		if len(layout.flags) != len(damageFlagDefs) || &layout.flags[0] != &damageFlagDefs[0] {
+			t.Errorf("%s flags do not use the damage flag set", layout.name)
+		}
+	}
+}
+
+func TestNewLayoutRejectsBadTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() error
+		wantErr string
+	}{
+		{"not a struct", layoutErr[uint32], "is not a struct"},
+		{"unknown kind", layoutErr[struct {
+			A uint32 `layout:"u24,at=0"`
+		}], `unknown kind "u24"`},
+		{"option without value", layoutErr[struct {
+			A uint32 `layout:"u32,at"`
+		}], `invalid option "at"`},
+		{"non-numeric offset", layoutErr[struct {
+			A uint32 `layout:"u32,at=x"`
+		}], `invalid option "at=x"`},
+		{"negative offset", layoutErr[struct {
+			A uint32 `layout:"u32,at=-1"`
+		}], `invalid option "at=-1"`},
+		{"unknown option", layoutErr[struct {
+			A uint32 `layout:"u32,offset=4"`
+		}], `unknown option "offset"`},
+		{"size on integer", layoutErr[struct {
+			A uint32 `layout:"u32,size=2"`
+		}], "size option is only valid for flags"},
+		{"bit without mask", layoutErr[struct {
+			A bool `layout:"bit,at=0"`
+		}], "bit field requires mask"},
+		{"zero mask", layoutErr[struct {
+			A bool `layout:"bit,at=0,mask=0"`
+		}], `invalid mask "0"`},
+		{"wide mask", layoutErr[struct {
+			A bool `layout:"bit,at=0,mask=0x100"`
+		}], `invalid mask "0x100"`},
+		{"flags without set", layoutErr[struct {
+			A DamageFlags `layout:"flags,at=0,size=7"`
+		}], "flags field requires set and size"},
+		{"flags without size", layoutErr[struct {
+			A DamageFlags `layout:"flags,at=0,set=damage"`
+		}], "flags field requires set and size"},
+		{"unknown flag set", layoutErr[struct {
+			A DamageFlags `layout:"flags,at=0,size=7,set=heal"`
+		}], `unknown flag set "heal"`},
+		{"integer too narrow", layoutErr[struct {
+			A uint16 `layout:"u32,at=0"`
+		}], "type uint16 does not match layout kind"},
+		{"signed integer", layoutErr[struct {
+			A int32 `layout:"u32,at=0"`
+		}], "type int32 does not match layout kind"},
+		{"string as integer", layoutErr[struct {
+			A uint32 `layout:"str32,at=0"`
+		}], "type uint32 does not match layout kind"},
+		{"flags too narrow", layoutErr[struct {
+			A uint8 `layout:"flags,at=0,size=7,set=damage"`
+		}], "type uint8 does not match layout kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.build()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewLayout error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func layoutErr[T any]() error {
+	_, err := NewLayout[T]("test")
+	return err
+}
+
+func TestMustNewLayoutPanicsOnBadTag(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("mustNewLayout did not panic")
+		}
+	}()
+
+	mustNewLayout[struct {
+		A uint32 `layout:"u24"`
+	}]("test")
+}
+
+func TestLayoutSkipsUntaggedFields(t *testing.T) {
+	layout, err := NewLayout[struct {
+		A        uint16 `layout:"u16,at=2"`
+		Computed uint32
+		Ignored  string `layout:"-"`
+		B        uint8  `layout:"u8,skip=1"`
+	}]("test")
+	if err != nil {
+		t.Fatalf("NewLayout: %v", err)
+	}
+
+	got, err := layout.Decode([]byte{0, 0, 0x34, 0x12, 0xff, 0x56})
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if got.A != 0x1234 || got.B != 0x56 || got.Computed != 0 || got.Ignored != "" {
+		t.Fatalf("Decode = %+v", got)
+	}
+}
@@ -0,0 +1,158 @@
+package packet
+
+import (
+	"encoding/binary"
+	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 송신 세그먼트에서 플레이어 ID를 찾을 최대 길이
+	localPlayerScanLength = 64
+	// 로컬 플레이어로 확정하기 위한 최소 득표 수
+	localPlayerMinVotes = 5
+	// 스킬 사용자로 기록된 ID를 후보로 유지하는 시간
+	localPlayerCandidateTTL = 10 * time.Minute
+	// 오래된 후보를 정리하는 최소 간격
+	localPlayerSweepInterval = time.Minute
+)
+
+var (
+	// 이벤트에 실리는 로컬 플레이어 ID (설정값이 있으면 설정값, 없으면 추론값)
+	localPlayerID atomic.Uint32
+
+	localPlayerMu       sync.Mutex
+	localPlayerOverride uint32
+	localPlayerInferred uint32
+	localPlayerVotes    = make(map[uint32]int)
+	playerCandidates    = make(map[uint32]time.Time)
+	candidatesSwept     time.Time
+)
+
+// SetLocalPlayer 로컬 플레이어 ID를 직접 지정합니다. 0이면 송신 세그먼트로 추론한 값을 사용합니다.
+func SetLocalPlayer(id uint32) {
+	localPlayerMu.Lock()
+	defer localPlayerMu.Unlock()
+
+	localPlayerOverride = id
+	if id != 0 {
+		clear(localPlayerVotes)
+	}
+	updateLocalPlayer()
+}
+
+// LocalPlayer 로컬 플레이어 ID와 그 값이 추론된 것인지를 반환합니다. 알 수 없으면 0입니다.
+func LocalPlayer() (id uint32, inferred bool) {
+	localPlayerMu.Lock()
+	defer localPlayerMu.Unlock()
+
+	if localPlayerOverride != 0 {
+		return localPlayerOverride, false
+	}
+	return localPlayerInferred, localPlayerInferred != 0
+}
+
+// observeLocalPlayer 수신한 스킬 사용자를 후보로 기록하고,
+// 송신 세그먼트에 후보 ID가 실려 있으면 그 후보에 투표합니다.
+// 게임 서버가 보내는 데이터는 파티 전체에 대한 것이지만 클라이언트는 자신의 ID만 보낸다는 점을 이용합니다.
+// 후보와 득표는 캡처 시각 기준으로 localPlayerCandidateTTL 동안 보이지 않으면 지워집니다.
+func observeLocalPlayer(data AnalyzedData, parsed any, timestamp time.Time) {
+	localPlayerMu.Lock()
+	defer localPlayerMu.Unlock()
+
+	sweepPlayerCandidates(timestamp)
+
+	switch data.Direction {
+	case DirectionServerToClient:
+		switch payload := parsed.(type) {
+		case ActionData:
+			playerCandidates[payload.UserID] = timestamp
+		case ItemData:
+			playerCandidates[payload.OwnerID] = timestamp
+		}
+
+	case DirectionClientToServer:
+		// ID를 직접 지정했으면 추론하지 않습니다
+		if localPlayerOverride == 0 {
+			voteLocalPlayer(data.Content)
+		}
+	}
+}
+
+func voteLocalPlayer(content []byte) {
+	scan := content[:min(len(content), localPlayerScanLength)]
+
+	// 한 세그먼트 안에서 같은 ID가 여러 번 나와도 한 표만 셉니다
+	var voted [4]uint32
+	votes := 0
+	for i := 0; i+4 <= len(scan) && votes < len(voted); i++ {
+		id := binary.LittleEndian.Uint32(scan[i:])
+		if _, ok := playerCandidates[id]; !ok || id == 0 || slices.Contains(voted[:votes], id) {
+			continue
+		}
+
+		voted[votes] = id
+		votes++
+		localPlayerVotes[id]++
+	}
+
+	if votes > 0 {
+		inferLocalPlayer()
+	}
+}
+
+// inferLocalPlayer 득표가 가장 많고 두 번째 후보의 두 배 이상인 후보를 로컬 플레이어로 봅니다.
+// 결정되면 득표를 초기화해 이후 캐릭터가 바뀌어도 그때부터의 득표만으로 다시 결정합니다.
+func inferLocalPlayer() {
+	var (
+		leader                     uint32
+		leaderVotes, runnerUpVotes int
+	)
+	for id, votes := range localPlayerVotes {
+		switch {
+		case votes > leaderVotes:
+			leader, leaderVotes, runnerUpVotes = id, votes, leaderVotes
+		case votes > runnerUpVotes:
+			runnerUpVotes = votes
+		}
+	}
+
+	if leaderVotes < localPlayerMinVotes || leaderVotes < 2*runnerUpVotes {
+		return
+	}
+
+	clear(localPlayerVotes)
+	if leader == localPlayerInferred {
+		return
+	}
+
+	log.Printf("Local player inferred: %d (%d votes, runner-up %d)", leader, leaderVotes, runnerUpVotes)
+	localPlayerInferred = leader
+	updateLocalPlayer()
+}
+
+func updateLocalPlayer() {
+	if localPlayerOverride != 0 {
+		localPlayerID.Store(localPlayerOverride)
+		return
+	}
+	localPlayerID.Store(localPlayerInferred)
+}
+
+func sweepPlayerCandidates(now time.Time) {
+	if now.Sub(candidatesSwept) < localPlayerSweepInterval {
+		return
+	}
+	candidatesSwept = now
+
+	cutoff := now.Add(-localPlayerCandidateTTL)
+	for id, seen := range playerCandidates {
+		if seen.Before(cutoff) {
+			delete(playerCandidates, id)
+			delete(localPlayerVotes, id)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package packet
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func resetLocalPlayer(t *testing.T) {
+	t.Helper()
+
+	reset := func() {
+		localPlayerMu.Lock()
+		defer localPlayerMu.Unlock()
+
+		localPlayerOverride = 0
+		localPlayerInferred = 0
+		clear(localPlayerVotes)
+		clear(playerCandidates)
+		candidatesSwept = time.Time{}
+		localPlayerID.Store(0)
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func observeCast(timestamp time.Time, userID uint32) {
+	observeLocalPlayer(AnalyzedData{Direction: DirectionServerToClient}, ActionData{UserID: userID}, timestamp)
+}
+
+// observeOutbound ids를 차례로 담은 송신 세그먼트를 관찰합니다
+func observeOutbound(timestamp time.Time, ids ...uint32) {
+	content := make([]byte, 0, 4*len(ids))
+	for _, id := range ids {
+		content = binary.LittleEndian.AppendUint32(content, id)
+	}
+	observeLocalPlayer(AnalyzedData{Direction: DirectionClientToServer, Content: content}, nil, timestamp)
+}
+
+func localPlayerState() (votes, candidates int) {
+	localPlayerMu.Lock()
+	defer localPlayerMu.Unlock()
+
+	return len(localPlayerVotes), len(playerCandidates)
+}
+
+func TestLocalPlayerInference(t *testing.T) {
+	resetLocalPlayer(t)
+	start := time.Unix(1700000000, 0)
+
+	observeCast(start, 1001)
+	observeCast(start, 1002)
+	for i := range localPlayerMinVotes - 1 {
+		observeOutbound(start.Add(time.Duration(i)*time.Second), 1001)
+	}
+	if id, _ := LocalPlayer(); id != 0 {
+		t.Fatalf("inferred %d before %d votes", id, localPlayerMinVotes)
+	}
+
+	observeOutbound(start.Add(time.Minute), 1001, 1001, 9999)
+	if id, inferred := LocalPlayer(); id != 1001 || !inferred || localPlayerID.Load() != 1001 {
+		t.Fatalf("LocalPlayer() = %d, %v, want inferred 1001", id, inferred)
+	}
+
+	// 결정되면 득표를 초기화합니다
+	if votes, _ := localPlayerState(); votes != 0 {
+		t.Fatalf("%d vote entries kept after the player was inferred", votes)
+	}
+}
+
+func TestLocalPlayerSwitchesCharacter(t *testing.T) {
+	resetLocalPlayer(t)
+	start := time.Unix(1700000000, 0)
+
+	observeCast(start, 1001)
+	for range 4 * localPlayerMinVotes {
+		observeOutbound(start, 1001)
+	}
+
+	// 다른 캐릭터로 바꾸면 이전 캐릭터의 누적 득표와 관계없이 다시 추론합니다
+	observeCast(start.Add(time.Minute), 2002)
+	for range localPlayerMinVotes {
+		observeOutbound(start.Add(time.Minute), 2002)
+	}
+	if id, _ := LocalPlayer(); id != 2002 {
+		t.Fatalf("LocalPlayer() = %d after switching, want 2002", id)
+	}
+}
+
+func TestLocalPlayerExpiresCandidates(t *testing.T) {
+	resetLocalPlayer(t)
+	start := time.Unix(1700000000, 0)
+
+	observeCast(start, 1001)
+	observeCast(start, 1002)
+	observeOutbound(start, 1001, 1002)
+	observeCast(start.Add(localPlayerCandidateTTL), 1003)
+
+	// 송신 세그먼트만 이어져도 오래된 후보와 그 득표가 지워져야 합니다
+	observeOutbound(start.Add(localPlayerCandidateTTL+2*time.Minute), 1003)
+	votes, candidates := localPlayerState()
+	if votes != 1 || candidates != 1 {
+		t.Fatalf("kept %d votes and %d candidates, want only 1003", votes, candidates)
+	}
+
+	localPlayerMu.Lock()
+	_, ok := playerCandidates[1003]
+	localPlayerMu.Unlock()
+	if !ok {
+		t.Fatal("recent candidate 1003 was expired")
+	}
+}
+
+func TestLocalPlayerOverrideSkipsVoting(t *testing.T) {
+	resetLocalPlayer(t)
+	start := time.Unix(1700000000, 0)
+
+	observeCast(start, 1001)
+	observeOutbound(start, 1001)
+	SetLocalPlayer(42)
+
+	for range 2 * localPlayerMinVotes {
+		observeOutbound(start, 1001)
+	}
+	if votes, _ := localPlayerState(); votes != 0 {
+		t.Fatalf("%d vote entries recorded with an override", votes)
+	}
+	if id, inferred := LocalPlayer(); id != 42 || inferred || localPlayerID.Load() != 42 {
+		t.Fatalf("LocalPlayer() = %d, %v, want 42 from the override", id, inferred)
+	}
+
+	SetLocalPlayer(0)
+	if id, _ := LocalPlayer(); id != 0 {
+		t.Fatalf("LocalPlayer() = %d after clearing the override, want 0", id)
+	}
+}
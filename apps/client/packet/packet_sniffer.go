@@ -8,22 +8,31 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
-	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
 	"github.com/google/gopacket/reassembly"
 	"github.com/shirou/gopsutil/v3/mem"
 )
 
+// 재조립기 flush 주기와 기준
+const (
+	flushInterval = 5 * time.Second
+	flushTimeout  = 6 * time.Second // 이 시간 동안 데이터가 없던 스트림은 버퍼를 내보냅니다
+	closeTimeout  = 4 * time.Second // 이 시간 동안 데이터가 없던 스트림은 닫습니다
+)
+
 var (
-	handle    *pcap.Handle
-	assembler *reassembly.Assembler
-	ctx       context.Context
-	wg        sync.WaitGroup
-	pcapFile  string // PCAP 파일 경로 (동적으로 설정)
+	sources     []*captureSource // 캡처 핸들들 (라이브 인터페이스들 또는 순서대로 읽을 PCAP 파일들)
+	assembler   *reassembly.Assembler
+	ctx         context.Context
+	wg          sync.WaitGroup
+	pcapFiles   []string // PCAP 파일 경로들 (동적으로 설정)
+	liveDevices []string // 라이브 캡처 인터페이스들 (이름, IP 주소 또는 auto). 비어 있으면 auto
+	captureBPF  string   // BPF 필터. 비어 있으면 프로토콜 프로필의 포트로 만듭니다
 )
 
 // getDefaultPcapPath 기본 PCAP 파일 경로를 반환합니다
@@ -44,12 +53,41 @@ func getDefaultPcapPath() string {
 }
 
 func init() {
-	pcapFile = getDefaultPcapPath()
+	pcapFiles = []string{getDefaultPcapPath()}
 }
 
 // SetPcapFile PCAP 파일 경로를 설정합니다
 func SetPcapFile(filePath string) {
-	pcapFile = filePath
+	pcapFiles = []string{filePath}
+}
+
+// SetPcapFiles 순서대로 읽을 PCAP 파일 경로들을 설정합니다
+func SetPcapFiles(filePaths []string) {
+	pcapFiles = append([]string(nil), filePaths...)
+}
+
+// SetInterface 라이브 캡처 인터페이스를 이름, IP 주소 또는 InterfaceAuto로 설정합니다.
+// 비어 있으면 InterfaceAuto와 같습니다.
+func SetInterface(name string) {
+	liveDevices = []string{name}
+}
+
+// SetInterfaces 동시에 캡처할 인터페이스들을 설정합니다. 각 값의 형식은 SetInterface와 같습니다.
+func SetInterfaces(names []string) {
+	liveDevices = append([]string(nil), names...)
+}
+
+// SetBPFFilter 캡처 BPF 필터를 설정합니다. 비어 있으면 프로토콜 프로필의 포트로 만듭니다.
+func SetBPFFilter(filter string) {
+	captureBPF = filter
+}
+
+// captureFilter 캡처 핸들에 적용할 BPF 필터를 반환합니다
+func captureFilter() string {
+	if captureBPF != "" {
+		return captureBPF
+	}
+	return bpfFilter()
 }
 
 // InitPacketSnifferLive 라이브 네트워크 캡처를 위한 초기화 (기존 기능)
@@ -63,136 +101,229 @@ func InitPacketSnifferLive(context context.Context) error {
 		return errors.New("no network devices found")
 	}
 
-	handle, err = pcap.OpenLive(devices[0].Name, 65535, true, pcap.BlockForever)
-	if err != nil {
-		return err
+	specs := liveDevices
+	if len(specs) == 0 {
+		specs = []string{InterfaceAuto}
 	}
 
-	if err := handle.SetBPFFilter("tcp and port 16000"); err != nil {
-		return err
-	}
+	for _, spec := range specs {
+		device, reason, err := selectInterface(devices, spec)
+		if err != nil {
+			return err
+		}
 
-	totalPages, pagesPerConnection, _ := calcOptimalParams()
+		if slices.ContainsFunc(sources, func(s *captureSource) bool { return s.name == device }) {
+			log.Printf("Network device %s selected more than once, capturing it once", device)
+			continue
+		}
+		log.Printf("Selected network device %s: %s", device, reason)
 
-	streamFactory := &tcpStreamFactory{}
-	streamPool := reassembly.NewStreamPool(streamFactory)
-	assembler = reassembly.NewAssembler(streamPool)
-	assembler.MaxBufferedPagesTotal = totalPages
-	assembler.MaxBufferedPagesPerConnection = pagesPerConnection
+		handle, err := pcap.OpenLive(device, 65535, true, pcap.BlockForever)
+		if err != nil {
+			return fmt.Errorf("failed to open network device %s: %w", device, err)
+		}
+		sources = append(sources, &captureSource{name: device, handle: handle, live: true})
+
+		if err := handle.SetBPFFilter(captureFilter()); err != nil {
+			return fmt.Errorf("invalid BPF filter %q: %w", captureFilter(), err)
+		}
+	}
 
+	assembler = newAssembler()
 	ctx = context
-	log.Printf("Initialized live packet capture on device: %s", devices[0].Name)
+	log.Printf("Initialized live packet capture on %d device(s)", len(sources))
 	return nil
 }
 
 func InitPacketSniffer(context context.Context) error {
-	// 라이브 네트워크 캡처 (주석처리)
-	/*
-		devices, err := pcap.FindAllDevs()
-		if err != nil {
-			return err
-		}
+	if len(pcapFiles) == 0 {
+		return errors.New("no pcap files configured")
+	}
 
-		if len(devices) == 0 {
-			return errors.New("no network devices found")
+	for _, pcapFile := range pcapFiles {
+		// PCAP 파일 존재 확인
+		if _, err := os.Stat(pcapFile); os.IsNotExist(err) {
+			return fmt.Errorf("pcap file does not exist: %s", pcapFile)
 		}
 
-		handle, err = pcap.OpenLive(devices[0].Name, 65535, true, pcap.BlockForever)
+		// PCAP 파일 읽기
+		handle, err := pcap.OpenOffline(pcapFile)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to open pcap file %s: %w", pcapFile, err)
 		}
+		sources = append(sources, &captureSource{name: pcapFile, handle: handle})
 
-		if err := handle.SetBPFFilter("tcp and port 16000"); err != nil {
-			return err
-		}
-	*/
-
-	// PCAP 파일 존재 확인
-	if _, err := os.Stat(pcapFile); os.IsNotExist(err) {
-		return fmt.Errorf("pcap file does not exist: %s", pcapFile)
-	}
+		log.Printf("Reading PCAP file: %s", pcapFile)
 
-	// PCAP 파일 읽기
-	var err error
-	handle, err = pcap.OpenOffline(pcapFile)
-	if err != nil {
-		return fmt.Errorf("failed to open pcap file %s: %w", pcapFile, err)
+		// BPF 필터 적용 (PCAP 파일에도 동일하게 적용)
+		if err := handle.SetBPFFilter(captureFilter()); err != nil {
+			return fmt.Errorf("invalid BPF filter %q: %w", captureFilter(), err)
+		}
 	}
 
-	log.Printf("Reading PCAP file: %s", pcapFile)
-
-	// BPF 필터 적용 (PCAP 파일에도 동일하게 적용)
-	if err := handle.SetBPFFilter("tcp and port 16000"); err != nil {
-		return err
-	}
+	assembler = newAssembler()
+	ctx = context
+	return nil
+}
 
+// newAssembler 메모리에 맞춘 버퍼 한도로 새 재조립기를 만듭니다
+func newAssembler() *reassembly.Assembler {
 	totalPages, pagesPerConnection, _ := calcOptimalParams()
 
 	streamFactory := &tcpStreamFactory{}
 	streamPool := reassembly.NewStreamPool(streamFactory)
-	assembler = reassembly.NewAssembler(streamPool)
+	assembler := reassembly.NewAssembler(streamPool)
 	assembler.MaxBufferedPagesTotal = totalPages
 	assembler.MaxBufferedPagesPerConnection = pagesPerConnection
-
-	ctx = context
-	return nil
+	return assembler
 }
 
 func StartPacketSniffer() {
+	packets := make(chan capturedPacket, packetQueueLength)
+	go readSources(packets)
+
+	live := len(sources) > 0 && sources[0].live
+
+	var dedup *packetDeduplicator
+	if len(sources) > 1 && live {
+		dedup = newPacketDeduplicator()
+	}
+
+	// 재조립기는 고루틴 안전하지 않으므로 한 고루틴에서만 사용합니다
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		assemblePackets(packets, live, dedup)
+	}()
+}
 
-		packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
-		ticker := time.NewTicker(5 * time.Second)
+// assemblePackets packets가 닫히거나 ctx가 취소될 때까지 패킷을 재조립기에 넣습니다.
+// 라이브 캡처는 실제 시각으로 주기적으로 flush하고,
+// PCAP 파일은 읽는 속도와 관계없이 같은 결과가 나오도록 캡처 시각으로 flush합니다.
+func assemblePackets(packets <-chan capturedPacket, live bool, dedup *packetDeduplicator) {
+	var ticks <-chan time.Time
+	if live {
+		ticker := time.NewTicker(flushInterval)
 		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
+		ticks = ticker.C
+	}
+	var clock packetClock
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticks:
+			flushStreams(now)
+		case captured, ok := <-packets:
+			if !ok {
 				return
-			case <-ticker.C:
-				assembler.FlushWithOptions(reassembly.FlushOptions{
-					T:  time.Now().Add(-6 * time.Second),
-					TC: time.Now().Add(-4 * time.Second),
-				})
-			case packet, ok := <-packetSource.Packets():
-				if !ok {
-					return
-				}
+			}
 
-				tcpLayer := packet.Layer(layers.LayerTypeTCP)
-				if tcpLayer == nil {
-					continue
-				}
+			if captured.restart {
+				restartStreams(&clock)
+				continue
+			}
 
-				tcp, ok := tcpLayer.(*layers.TCP)
-				if !ok {
-					continue
-				}
+			assemblePacket(captured, dedup)
 
-				assembler.AssembleWithContext(
-					packet.NetworkLayer().NetworkFlow(),
-					tcp,
-					&Context{
-						CaptureInfo: packet.Metadata().CaptureInfo,
-						TCP:         tcp,
-					},
-				)
+			if !live {
+				if now, ok := clock.advance(captured.packet.Metadata().Timestamp); ok {
+					flushStreams(now)
+				}
 			}
 		}
-	}()
+	}
 }
 
-func StopPacketSniffer() {
+// restartStreams 재생을 처음부터 다시 시작하므로 이전 스트림을 모두 내보내고 새 재조립기를 사용합니다.
+// 구독자가 쌓아 둔 상태를 비울 수 있도록 StreamReset 이벤트를 발행합니다.
+func restartStreams(clock *packetClock) {
 	assembler.FlushAll()
+	assembler = newAssembler()
+
+	publish(Event{Name: streamResetName, Payload: StreamReset{}, Timestamp: clock.now})
+	*clock = packetClock{}
+}
+
+// flushStreams now 기준으로 오래 데이터가 없던 스트림을 flush하거나 닫습니다
+func flushStreams(now time.Time) {
+	assembler.FlushWithOptions(reassembly.FlushOptions{
+		T:  now.Add(-flushTimeout),
+		TC: now.Add(-closeTimeout),
+	})
+}
+
+// packetClock 마지막으로 본 패킷의 캡처 시각으로 흐르는 시계. flushInterval마다 flush 시점을 알립니다.
+type packetClock struct {
+	now       time.Time
+	nextFlush time.Time
+}
+
+// advance timestamp로 시계를 진행하고, flush할 때가 되면 현재 시각과 true를 반환합니다.
+// 캡처 시각이 뒤로 가는 패킷은 시계를 되돌리지 않습니다.
+func (c *packetClock) advance(timestamp time.Time) (time.Time, bool) {
+	if timestamp.After(c.now) {
+		c.now = timestamp
+	}
+
+	if c.nextFlush.IsZero() {
+		c.nextFlush = c.now.Add(flushInterval)
+		return c.now, false
+	}
+
+	if c.now.Before(c.nextFlush) {
+		return c.now, false
+	}
+
+	c.nextFlush = c.now.Add(flushInterval)
+	return c.now, true
+}
+
+// assemblePacket TCP 패킷을 재조립기에 넣습니다. dedup이 있으면 다른 인터페이스에서 이미 본 패킷은 버립니다.
+func assemblePacket(captured capturedPacket, dedup *packetDeduplicator) {
+	packet := captured.packet
+
+	tcpLayer := packet.Layer(layers.LayerTypeTCP)
+	if tcpLayer == nil {
+		return
+	}
+
+	tcp, ok := tcpLayer.(*layers.TCP)
+	if !ok {
+		return
+	}
+
+	net := packet.NetworkLayer().NetworkFlow()
+	captureInfo := packet.Metadata().CaptureInfo
+	if dedup != nil && dedup.duplicate(net, tcp, captureInfo.Timestamp) {
+		captured.source.duplicates.Add(1)
+		return
+	}
+
+	assembler.AssembleWithContext(
+		net,
+		tcp,
+		&Context{
+			CaptureInfo: captureInfo,
+			TCP:         tcp,
+		},
+	)
+}
+
+func StopPacketSniffer() {
+	// 캡처 고루틴이 끝난 뒤에 flush해야 재조립기에 동시 접근하지 않습니다
 	wg.Wait()
+	assembler.FlushAll()
+	closeSubscriptions()
+	logCaptureStats()
 }
 
 func ClosePacketSniffer() {
-	if handle != nil {
-		handle.Close()
+	for _, source := range sources {
+		source.close()
 	}
+	sources = nil
 }
 
 func calcOptimalParams() (totalPages, perConnectionPages, optimalBuffer int) {
@@ -0,0 +1,174 @@
+package packet
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestRestartStreamsPublishesReset(t *testing.T) {
+	previous := assembler
+	assembler = newAssembler()
+	t.Cleanup(func() { assembler = previous })
+
+	events := Subscribe(1)
+	defer events.Close()
+
+	clock := packetClock{}
+	last := time.Unix(1700000000, 0)
+	clock.advance(last)
+
+	restartStreams(&clock)
+
+	select {
+	case event := <-events.C:
+		if _, ok := event.Payload.(StreamReset); !ok || event.Name != streamResetName || !event.Timestamp.Equal(last) {
+			t.Fatalf("event = %+v, want a stream reset at %v", event, last)
+		}
+	default:
+		t.Fatal("no stream reset event published")
+	}
+
+	if clock != (packetClock{}) {
+		t.Fatalf("clock = %+v after the restart, want zero", clock)
+	}
+}
+
+func TestPacketClockAdvance(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	steps := []struct {
+		offset    time.Duration
+		wantNow   time.Duration
+		wantFlush bool
+	}{
+		{0, 0, false},
+		{3 * time.Second, 3 * time.Second, false},
+		// 캡처 시각이 뒤로 가는 패킷은 시계를 되돌리지 않습니다
+		{2 * time.Second, 3 * time.Second, false},
+		{flushInterval, flushInterval, true},
+		{4 * time.Second, flushInterval, false},
+		{2*flushInterval - time.Millisecond, 2*flushInterval - time.Millisecond, false},
+		// 다음 flush는 마지막 flush 시각부터 flushInterval 뒤입니다
+		{2 * flushInterval, 2 * flushInterval, true},
+		{time.Minute, time.Minute, true},
+		{time.Minute + flushInterval - time.Millisecond, time.Minute + flushInterval - time.Millisecond, false},
+		{time.Second, time.Minute + flushInterval - time.Millisecond, false},
+	}
+
+	var clock packetClock
+	for i, step := range steps {
+		now, flush := clock.advance(start.Add(step.offset))
+		if !now.Equal(start.Add(step.wantNow)) || flush != step.wantFlush {
+			t.Fatalf("step %d: advance(+%s) = +%s, %v, want +%s, %v", i, step.offset, now.Sub(start), flush, step.wantNow, step.wantFlush)
+		}
+	}
+}
+
+// testReplayPackets 서버가 보낸 공격 프레임들 중 하나가 유실되어 뒤의 프레임들이 재조립기에 쌓였다가
+// 캡처 시각으로 flush되는 연결을 만듭니다
+func testReplayPackets(t *testing.T, start time.Time) []gopacket.Packet {
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	frames := make([][]byte, 6)
+	for i := range frames {
+		frames[i] = testAttackFrame(uint32(i + 1))
+	}
+
+	seq := make([]uint32, len(frames)+1)
+	seq[0] = 1001
+	for i, frame := range frames {
+		seq[i+1] = seq[i] + uint32(len(frame))
+	}
+
+	return []gopacket.Packet{
+		testTCPPacket(t, at(0), false, layers.TCP{Seq: 100, SYN: true}, nil),
+		testTCPPacket(t, at(10), true, layers.TCP{Seq: 1000, Ack: 101, SYN: true, ACK: true}, nil),
+		testTCPPacket(t, at(20), true, layers.TCP{Seq: seq[0], Ack: 101, ACK: true, PSH: true}, frames[0]),
+		// frames[1]은 캡처되지 않았습니다
+		testTCPPacket(t, at(1000), true, layers.TCP{Seq: seq[2], Ack: 101, ACK: true, PSH: true}, frames[2]),
+		testTCPPacket(t, at(2000), true, layers.TCP{Seq: seq[3], Ack: 101, ACK: true, PSH: true}, frames[3]),
+		testTCPPacket(t, at(9000), false, layers.TCP{Seq: 101, Ack: seq[0], ACK: true}, nil),
+		testTCPPacket(t, at(10000), true, layers.TCP{Seq: seq[4], Ack: 101, ACK: true, PSH: true}, frames[4]),
+		// 캡처 시각이 조금 뒤로 간 패킷
+		testTCPPacket(t, at(9990), true, layers.TCP{Seq: seq[5], Ack: 101, ACK: true, PSH: true}, frames[5]),
+	}
+}
+
+type replayedEvent struct {
+	UserID    uint32
+	Timestamp time.Time
+	Direction Direction
+}
+
+// replayTestPackets 재생 시계에 맞춰 packets를 재조립 루프로 보내고 발행된 이벤트를 반환합니다
+func replayTestPackets(t *testing.T, speed float64, packets []gopacket.Packet) []replayedEvent {
+	previousCtx, previousAssembler, previousReplay := ctx, assembler, replay
+	t.Cleanup(func() { ctx, assembler, replay = previousCtx, previousAssembler, previousReplay })
+
+	ctx = context.Background()
+	assembler = newAssembler()
+	replay = &replayClock{speed: speed, changed: make(chan struct{})}
+
+	events := Subscribe(len(packets))
+
+	captured := make(chan capturedPacket)
+	go func() {
+		defer close(captured)
+
+		source := &captureSource{name: "test.pcap"}
+		for _, packet := range packets {
+			if !replay.wait(packet.Metadata().Timestamp) {
+				return
+			}
+			captured <- capturedPacket{packet: packet, source: source}
+		}
+	}()
+
+	assemblePackets(captured, false, nil)
+	assembler.FlushAll()
+	events.Close()
+
+	var replayed []replayedEvent
+	for event := range events.C {
+		attack, ok := event.Payload.(AttackData)
+		if !ok {
+			t.Fatalf("unexpected event %+v", event)
+		}
+		replayed = append(replayed, replayedEvent{attack.UserID, event.Timestamp, event.Direction})
+	}
+
+	if dropped := events.Dropped(); dropped != 0 {
+		t.Fatalf("%d events dropped", dropped)
+	}
+	return replayed
+}
+
+func TestReplayPaceDoesNotChangeEvents(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	packets := testReplayPackets(t, start)
+
+	fast := replayTestPackets(t, ReplayMax, packets)
+	slow := replayTestPackets(t, 200, packets)
+
+	if !reflect.DeepEqual(fast, slow) {
+		t.Fatalf("events differ between replay speeds:\nmax:  %+v\n200x: %+v", fast, slow)
+	}
+
+	// 유실 뒤에 쌓인 프레임 3, 4는 캡처 시각 9초의 flush에서 나오고, 그 뒤의 프레임들은 곧바로 자기 캡처 시각으로 나옵니다.
+	// flush되지 않았다면 모두 마지막 FlushAll에서 첫 쌓인 패킷의 시각(1초)으로 나왔을 것입니다.
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	want := []replayedEvent{
+		{1, at(20), DirectionServerToClient},
+		{3, at(1000), DirectionServerToClient},
+		{4, at(1000), DirectionServerToClient},
+		{5, at(10000), DirectionServerToClient},
+		{6, at(9990), DirectionServerToClient},
+	}
+	if !reflect.DeepEqual(fast, want) {
+		t.Fatalf("events = %+v, want %+v", fast, want)
+	}
+}
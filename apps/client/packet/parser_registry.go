@@ -0,0 +1,168 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ParserInfo 파서의 부가 정보
+type ParserInfo struct {
+	Name      string // 로그와 이벤트에 사용할 이름 (비어 있으면 "type_<id>")
+	MinLength int    // 세그먼트가 이보다 짧으면 파서를 호출하지 않고 오류를 반환합니다
+}
+
+// Parser 데이터 타입 하나에 등록된 파서
+type Parser struct {
+	Type int
+	ParserInfo
+	parse   func(data []byte) (any, error)
+	builtin bool // 프로필로 등록된 내장 파서
+}
+
+// Parse 최소 길이를 확인한 뒤 세그먼트 내용을 파싱합니다.
+// 결과는 any로 반환되므로 파서가 할당 없이 값을 만들더라도 이벤트 발행 경로에서는 값이 한 번 힙에 박싱됩니다.
+func (p Parser) Parse(data []byte) (any, error) {
+	if len(data) < p.MinLength {
+		return nil, fmt.Errorf("%s segment too short: %d bytes, need at least %d", p.Name, len(data), p.MinLength)
+	}
+
+	return p.parse(data)
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = make(map[int]Parser)
+)
+
+var (
+	// 프로필의 타입 번호에 연결되는 내장 파서 (Type은 등록 시 채워집니다)
+	builtinParsers = map[string]Parser{
+		"attack":      newParser(0, ParserInfo{Name: "attack", MinLength: attackDataLength}, parseAttack),
+		"hp":          newParser(0, ParserInfo{Name: "hp", MinLength: hpDataMinLength}, parseHP),
+		"action":      newParser(0, ParserInfo{Name: "action", MinLength: actionDataMinLength}, parseAction),
+		"self_damage": newParser(0, ParserInfo{Name: "self_damage", MinLength: selfDamageDataMinLength}, parseSelfDamage),
+		"item":        newParser(0, ParserInfo{Name: "item", MinLength: itemDataMinLength}, parseItem),
+	}
+
+	// 현재 프로필로 등록된 내장 파서의 타입 번호들
+	builtinTypes []int
+)
+
+// RegisterParser dataType 세그먼트를 처리할 파서를 등록합니다.
+// 이미 파서가 등록된 타입이면 오류를 반환하므로, 교체하려면 먼저 UnregisterParser를 호출해야 합니다.
+// 내장 파서를 이렇게 교체한 타입은 이후 UseProfile에서도 교체한 파서가 유지됩니다.
+func RegisterParser[T any](dataType int, info ParserInfo, parse func(data []byte) (T, error)) error {
+	if dataType == 0 {
+		// 타입 0은 세그먼트 목록의 끝을 의미합니다
+		return errors.New("data type 0 is reserved")
+	}
+
+	if parse == nil {
+		return fmt.Errorf("nil parser for data type %d", dataType)
+	}
+
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	if existing, ok := parsers[dataType]; ok {
+		return fmt.Errorf("parser for data type %d already registered: %s", dataType, existing.Name)
+	}
+
+	parsers[dataType] = newParser(dataType, info, parse)
+	return nil
+}
+
+// UnregisterParser dataType에 등록된 파서를 제거합니다
+func UnregisterParser(dataType int) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	delete(parsers, dataType)
+}
+
+// LookupParser dataType에 등록된 파서를 반환합니다
+func LookupParser(dataType int) (Parser, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	parser, ok := parsers[dataType]
+	return parser, ok
+}
+
+// RegisteredParsers 등록된 모든 파서를 타입 순으로 반환합니다
+func RegisteredParsers() []Parser {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	list := make([]Parser, 0, len(parsers))
+	for _, parser := range parsers {
+		list = append(list, parser)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Type < list[j].Type
+	})
+
+	return list
+}
+
+// registerBuiltinParsers 이전 프로필의 내장 파서를 제거하고 profile의 타입 번호로 다시 등록합니다.
+// 이전 프로필의 타입에 직접 등록해 내장 파서를 교체한 파서는 그대로 둡니다.
+// 그 밖에 RegisterParser로 직접 등록된 파서와 타입 번호가 겹치면 오류를 반환합니다.
+func registerBuiltinParsers(profile Profile) error {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	previous := make(map[int]bool, len(builtinTypes))
+	for _, dataType := range builtinTypes {
+		previous[dataType] = true
+	}
+
+	for _, types := range profile.Types {
+		for _, dataType := range types {
+			if existing, ok := parsers[dataType]; ok && !previous[dataType] {
+				return fmt.Errorf("data type %d already registered: %s", dataType, existing.Name)
+			}
+		}
+	}
+
+	for _, dataType := range builtinTypes {
+		if parsers[dataType].builtin {
+			delete(parsers, dataType)
+		}
+	}
+	builtinTypes = builtinTypes[:0]
+
+	for name, types := range profile.Types {
+		parser := builtinParsers[name]
+		parser.builtin = true
+		for _, dataType := range types {
+			// 교체된 타입도 다음 프로필에서 교체한 파서를 유지할 수 있도록 목록에는 남깁니다
+			builtinTypes = append(builtinTypes, dataType)
+			if _, replaced := parsers[dataType]; replaced {
+				continue
+			}
+
+			parser.Type = dataType
+			parsers[dataType] = parser
+		}
+	}
+
+	return nil
+}
+
+func newParser[T any](dataType int, info ParserInfo, parse func(data []byte) (T, error)) Parser {
+	if info.Name == "" {
+		info.Name = fmt.Sprintf("type_%d", dataType)
+	}
+
+	return Parser{
+		Type:       dataType,
+		ParserInfo: info,
+		parse: func(data []byte) (any, error) {
+			return parse(data)
+		},
+	}
+}
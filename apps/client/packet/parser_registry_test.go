@@ -0,0 +1,190 @@
+package packet
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// 기본 프로필이 쓰지 않는 타입 번호
+const testExternalType = 777001
+
+type testExternalData struct {
+	Value uint32
+}
+
+func parseTestExternal(data []byte) (testExternalData, error) {
+	return testExternalData{Value: binary.LittleEndian.Uint32(data)}, nil
+}
+
+func registerTestExternal(t *testing.T) {
+	t.Helper()
+
+	if err := RegisterParser(testExternalType, ParserInfo{Name: "external", MinLength: 4}, parseTestExternal); err != nil {
+		t.Fatalf("RegisterParser: %v", err)
+	}
+	t.Cleanup(func() { UnregisterParser(testExternalType) })
+}
+
+func TestRegisterParserReachesAnalyzePayload(t *testing.T) {
+	registerTestExternal(t)
+
+	events := Subscribe(4)
+	defer events.Close()
+
+	timestamp := time.Unix(1700000000, 0)
+	content := binary.LittleEndian.AppendUint32(nil, 42)
+	analyzePayload([]AnalyzedData{{Type: testExternalType, Content: content}}, timestamp, gopacket.Flow{}, gopacket.Flow{})
+
+	select {
+	case event := <-events.C:
+		data, ok := event.Payload.(testExternalData)
+		if !ok || data.Value != 42 || event.Name != "external" || event.Type != testExternalType || !event.Timestamp.Equal(timestamp) {
+			t.Fatalf("event = %+v, want external value 42", event)
+		}
+	default:
+		t.Fatal("external parser produced no event")
+	}
+
+	parser, ok := LookupParser(testExternalType)
+	if !ok || parser.Type != testExternalType || parser.Name != "external" {
+		t.Fatalf("LookupParser = %+v, %v", parser, ok)
+	}
+
+	UnregisterParser(testExternalType)
+	if _, ok := LookupParser(testExternalType); ok {
+		t.Fatal("parser still registered after UnregisterParser")
+	}
+}
+
+func TestRegisterParserRejects(t *testing.T) {
+	registerTestExternal(t)
+
+	attackType := currentProfile().Types["attack"][0]
+	tests := []struct {
+		name     string
+		dataType int
+		parse    func([]byte) (testExternalData, error)
+		wantErr  string
+	}{
+		{"duplicate type", testExternalType, parseTestExternal, "already registered: external"},
+		{"reserved type 0", 0, parseTestExternal, "reserved"},
+		{"nil parser", testExternalType + 1, nil, "nil parser"},
+		{"type of the active profile", attackType, parseTestExternal, "already registered: attack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterParser(tt.dataType, ParserInfo{}, tt.parse)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("RegisterParser = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if parser, _ := LookupParser(attackType); parser.Name != "attack" {
+		t.Fatalf("builtin parser replaced by a rejected registration: %s", parser.Name)
+	}
+}
+
+func TestParserMinLength(t *testing.T) {
+	called := false
+	err := RegisterParser(testExternalType, ParserInfo{MinLength: 8}, func(data []byte) (testExternalData, error) {
+		called = true
+		return testExternalData{}, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterParser: %v", err)
+	}
+	t.Cleanup(func() { UnregisterParser(testExternalType) })
+
+	parser, _ := LookupParser(testExternalType)
+	if parser.Name != "type_777001" {
+		t.Fatalf("default name = %q", parser.Name)
+	}
+
+	if _, err := parser.Parse(make([]byte, 7)); err == nil || called {
+		t.Fatalf("Parse of 7 bytes = %v, called %v, want a length error without calling the parser", err, called)
+	}
+	if _, err := parser.Parse(make([]byte, 8)); err != nil || !called {
+		t.Fatalf("Parse of 8 bytes = %v, called %v", err, called)
+	}
+
+	// 짧은 세그먼트는 이벤트로 발행되지 않습니다
+	events := Subscribe(1)
+	defer events.Close()
+	analyzePayload([]AnalyzedData{{Type: testExternalType, Content: make([]byte, 4)}}, time.Now(), gopacket.Flow{}, gopacket.Flow{})
+	select {
+	case event := <-events.C:
+		t.Fatalf("short segment published %+v", event)
+	default:
+	}
+}
+
+func TestUseProfileConflictsWithRegisteredParser(t *testing.T) {
+	registerTestExternal(t)
+	t.Cleanup(func() {
+		if err := UseProfile(DefaultProfile()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	profile := DefaultProfile()
+	profile.Name = "conflicting"
+	profile.Types["hp"] = append(profile.Types["hp"], testExternalType)
+
+	err := UseProfile(profile)
+	if err == nil || !strings.Contains(err.Error(), "already registered: external") {
+		t.Fatalf("UseProfile = %v, want a conflict with the external parser", err)
+	}
+
+	// 실패한 프로필은 적용되지 않습니다
+	if currentProfile().Name != DefaultProfile().Name {
+		t.Fatalf("active profile = %s after a failed UseProfile", currentProfile().Name)
+	}
+	if parser, _ := LookupParser(testExternalType); parser.Name != "external" {
+		t.Fatalf("external parser replaced by %s", parser.Name)
+	}
+}
+
+func TestUseProfileKeepsReplacedBuiltinParser(t *testing.T) {
+	attackType := DefaultProfile().Types["attack"][0]
+	t.Cleanup(func() {
+		UnregisterParser(attackType)
+		if err := UseProfile(DefaultProfile()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	UnregisterParser(attackType)
+	if err := RegisterParser(attackType, ParserInfo{Name: "custom_attack"}, parseTestExternal); err != nil {
+		t.Fatalf("RegisterParser after UnregisterParser: %v", err)
+	}
+
+	// main은 시작할 때 항상 프로필을 다시 적용하므로 교체한 파서가 되돌아가면 안 됩니다
+	for range 2 {
+		if err := UseProfile(DefaultProfile()); err != nil {
+			t.Fatalf("UseProfile: %v", err)
+		}
+		if parser, _ := LookupParser(attackType); parser.Name != "custom_attack" {
+			t.Fatalf("LookupParser(%d) = %s after UseProfile, want custom_attack", attackType, parser.Name)
+		}
+	}
+
+	// 다른 내장 파서는 그대로 다시 등록됩니다
+	if parser, _ := LookupParser(DefaultProfile().Types["hp"][0]); parser.Name != "hp" {
+		t.Fatalf("hp parser = %s", parser.Name)
+	}
+
+	// 교체한 파서를 제거하면 다음 프로필 적용에서 내장 파서가 돌아옵니다
+	UnregisterParser(attackType)
+	if err := UseProfile(DefaultProfile()); err != nil {
+		t.Fatalf("UseProfile: %v", err)
+	}
+	if parser, _ := LookupParser(attackType); parser.Name != "attack" {
+		t.Fatalf("LookupParser(%d) = %s, want the builtin attack parser back", attackType, parser.Name)
+	}
+}
@@ -3,126 +3,168 @@ package packet
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"log"
-)
+	"net/netip"
+	"time"
 
-const (
-	attackDataType      = 10308
-	hpDataType          = 100178
-	actionDataType      = 100041
-	selfDamageDataType1 = 10701
-	selfDamageDataType2 = 10719
-	itemDataType1       = 100321
-	itemDataType2       = 100322
+	"github.com/google/gopacket"
 )
 
-var (
-	startDelimiter        = []byte{0x68, 0x27, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
-	endDelimiter          = []byte{0xe3, 0x27, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
-	segmentMetadataLength = 9
-)
+const segmentMetadataLength = 9
+
+// 압축 세그먼트 안에 다시 압축 세그먼트가 있는 경우를 대비한 재귀 깊이 제한
+const maxCompressionDepth = 4
 
 type AnalyzedData struct {
-	Type    int
-	Content []byte
+	Type      int
+	Content   []byte
+	Err       error          // 세그먼트 단위 오류 (예: 압축 해제 실패)
+	Direction Direction      // 스트림에서 디코딩된 경우에만 설정됩니다
+	Server    netip.AddrPort // 게임 서버 종단점 (스트림에서 디코딩된 경우에만 설정됩니다)
 }
 
+// AnalyzePayload 페이로드에서 완결된 프레임들의 세그먼트를 추출합니다.
+// 끝 구분자가 없는 미완성 프레임은 버려지므로 스트림 단위 처리는 frameDecoder를 사용해야 합니다.
 func AnalyzePayload(payload []byte) []AnalyzedData {
-	payloadLength := len(payload)
-
-	if payloadLength == 0 {
-		return nil
-	}
+	analyzed, _ := analyzeFrames(payload, currentProfile())
+	return analyzed
+}
 
-	var analyzed []AnalyzedData
-	consumed := 0
+// analyzeFrames 페이로드에서 완결된 프레임들을 분석하고, 아직 처리되지 않은(미완성 프레임이 시작되는) 위치를 함께 반환합니다.
+// consumed 이후의 바이트는 다음 데이터와 이어 붙여 다시 분석해야 합니다.
+func analyzeFrames(payload []byte, profile *Profile) (analyzed []AnalyzedData, consumed int) {
+	payloadLength := len(payload)
+	startDelimiter, endDelimiter := profile.StartDelimiter, profile.EndDelimiter
 
 	for consumed < payloadLength {
 		relStart := bytes.Index(payload[consumed:], startDelimiter)
 		if relStart < 0 {
+			// 다음 데이터와 합쳐져 시작 구분자가 될 수 있는 꼬리 부분은 남겨둡니다
+			if tail := payloadLength - (len(startDelimiter) - 1); tail > consumed {
+				consumed = tail
+			}
 			break
 		}
 		startIdx := consumed + relStart
 
 		scanFrom := startIdx + len(startDelimiter)
-		if scanFrom > payloadLength {
-			break
-		}
 		relEnd := bytes.Index(payload[scanFrom:], endDelimiter)
 		if relEnd < 0 {
+			consumed = startIdx
 			break
 		}
 		endIdx := scanFrom + relEnd
 
-		for segStart := scanFrom; ; {
-			metaEnd := segStart + segmentMetadataLength
-			if metaEnd > endIdx {
-				break
-			}
+		analyzed = analyzeSegments(payload[scanFrom:endIdx], 0, analyzed)
 
-			dataType := int(binary.LittleEndian.Uint32(payload[segStart : segStart+4]))
-			dataLength := int(binary.LittleEndian.Uint32(payload[segStart+4 : segStart+8]))
-			dataEncoding := payload[segStart+8]
+		consumed = endIdx + len(endDelimiter)
+	}
 
-			if dataType == 0 {
-				break
-			}
+	return analyzed, consumed
+}
 
-			segStart = metaEnd + dataLength
+// analyzeSegments 타입/길이 메타데이터로 구분된 세그먼트들을 순회하며 결과를 analyzed에 추가합니다.
+// 압축된 세그먼트는 해제한 뒤 같은 방식으로 재귀 순회합니다.
+func analyzeSegments(data []byte, depth int, analyzed []AnalyzedData) []AnalyzedData {
+	dataLength := len(data)
 
-			if segStart > endIdx {
-				break
-			}
+	for segStart := 0; ; {
+		metaEnd := segStart + segmentMetadataLength
+		if metaEnd > dataLength {
+			break
+		}
 
-			if dataEncoding != 0 {
-				// TODO Brotli 압축 파싱
-				continue
-			}
+		dataType := int(binary.LittleEndian.Uint32(data[segStart : segStart+4]))
+		contentLength := binary.LittleEndian.Uint32(data[segStart+4 : segStart+8])
+		dataEncoding := data[segStart+8]
 
-			analyzed = append(analyzed, AnalyzedData{
-				Type:    dataType,
-				Content: payload[metaEnd:segStart],
-			})
+		if dataType == 0 {
+			break
 		}
 
-		consumed = endIdx + len(endDelimiter)
+		if uint64(contentLength) > uint64(dataLength-metaEnd) {
+			break
+		}
+
+		segStart = metaEnd + int(contentLength)
+		content := data[metaEnd:segStart]
+
+		if dataEncoding != 0 {
+			analyzed = analyzeCompressedSegment(dataType, content, depth, analyzed)
+			continue
+		}
+
+		analyzed = append(analyzed, AnalyzedData{
+			Type:    dataType,
+			Content: content,
+		})
 	}
 
 	return analyzed
 }
 
-func analyzePayload(payload []byte) {
-	datas := AnalyzePayload(payload)
+// analyzeCompressedSegment Brotli로 압축된 세그먼트를 해제하고 내부 세그먼트들을 analyzed에 추가합니다.
+// 해제에 실패하면 해당 세그먼트만 Err가 설정된 항목으로 남기고 나머지 프레임 처리는 계속됩니다.
+func analyzeCompressedSegment(dataType int, content []byte, depth int, analyzed []AnalyzedData) []AnalyzedData {
+	if depth >= maxCompressionDepth {
+		return append(analyzed, AnalyzedData{
+			Type:    dataType,
+			Content: content,
+			Err:     fmt.Errorf("compressed segment nested too deep (type %d, depth %d)", dataType, depth),
+		})
+	}
 
-	for _, data := range datas {
-		switch data.Type {
-		case attackDataType:
-			parsed, err := parseAttack(data.Content)
-			if err != nil {
-				log.Printf("parseAttack error: %v", err)
-			}
+	decompressed, err := decompressBrotli(content)
+	if err != nil {
+		return append(analyzed, AnalyzedData{
+			Type:    dataType,
+			Content: content,
+			Err:     fmt.Errorf("failed to decompress segment (type %d, %d bytes): %w", dataType, len(content), err),
+		})
+	}
 
-			log.Printf("attack: %+v", parsed)
+	return analyzeSegments(decompressed, depth+1, analyzed)
+}
 
-		case hpDataType:
-			parsed, err := parseHP(data.Content)
-			if err != nil {
-				log.Printf("parseHP error: %v", err)
-			}
+// analyzePayload 세그먼트들을 등록된 파서로 파싱해 구독자에게 이벤트로 발행합니다
+func analyzePayload(datas []AnalyzedData, timestamp time.Time, net, transport gopacket.Flow) {
+	for _, data := range datas {
+		if data.Err != nil {
+			log.Printf("segment error: %v", data.Err)
+			continue
+		}
 
-			log.Printf("hp: %+v", parsed)
+		parser, ok := LookupParser(data.Type)
+		if !ok {
+			observeLocalPlayer(data, nil, timestamp)
+			recordUnknown(data, timestamp)
+			continue
+		}
 
-		case actionDataType:
-			parsed, err := parseAction(data.Content)
-			if err != nil {
-				log.Printf("parseAction error: %v", err)
-			}
+		parsed, err := parser.Parse(data.Content)
+		if err != nil {
+			log.Printf("%s parse error: %v", parser.Name, err)
+			continue
+		}
 
-			log.Printf("action: %+v", parsed)
-		case selfDamageDataType1, selfDamageDataType2:
-			parseSelfDamage(data.Content)
-		case itemDataType1, itemDataType2:
-			parseItem(data.Content)
+		observeLocalPlayer(data, parsed, timestamp)
+
+		if parsed == nil {
+			continue
 		}
+
+		publish(Event{
+			Type:      data.Type,
+			Name:      parser.Name,
+			Payload:   parsed,
+			Timestamp: timestamp,
+			Net:       net,
+			Transport: transport,
+			Direction: data.Direction,
+			Server:    data.Server,
+
+			LocalPlayerID: localPlayerID.Load(),
+		})
 	}
 }
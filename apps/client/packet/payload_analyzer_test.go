@@ -0,0 +1,180 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+// testSegment 압축되지 않은 세그먼트 하나를 타입/길이 메타데이터와 함께 인코딩합니다
+func testSegment(dataType int, content []byte) []byte {
+	segment := make([]byte, segmentMetadataLength, segmentMetadataLength+len(content))
+	binary.LittleEndian.PutUint32(segment[0:4], uint32(dataType))
+	binary.LittleEndian.PutUint32(segment[4:8], uint32(len(content)))
+
+	return append(segment, content...)
+}
+
+// testFrame 세그먼트들을 프로필의 시작/끝 구분자로 감싼 프레임을 만듭니다
+func testFrame(profile *Profile, segments ...[]byte) []byte {
+	frame := bytes.Clone(profile.StartDelimiter)
+	for _, segment := range segments {
+		frame = append(frame, segment...)
+	}
+
+	return append(frame, profile.EndDelimiter...)
+}
+
+func testProfile() *Profile {
+	profile := DefaultProfile()
+	return &profile
+}
+
+func decodedContents(analyzed []AnalyzedData) []string {
+	contents := make([]string, len(analyzed))
+	for i, data := range analyzed {
+		contents[i] = string(data.Content)
+	}
+
+	return contents
+}
+
+func assertContents(t *testing.T, analyzed []AnalyzedData, want ...string) {
+	t.Helper()
+
+	got := decodedContents(analyzed)
+	if len(got) != len(want) {
+		t.Fatalf("decoded %d segments %q, want %q", len(got), got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("segment %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// testCompressedSegment 세그먼트들을 Brotli로 압축해 압축 세그먼트 하나로 인코딩합니다
+func testCompressedSegment(t *testing.T, dataType int, segments ...[]byte) []byte {
+	t.Helper()
+
+	var compressed bytes.Buffer
+	writer := brotli.NewWriter(&compressed)
+	for _, segment := range segments {
+		if _, err := writer.Write(segment); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segment := testSegment(dataType, compressed.Bytes())
+	segment[8] = 1
+	return segment
+}
+
+func analyzedTypes(analyzed []AnalyzedData) []int {
+	types := make([]int, len(analyzed))
+	for i, data := range analyzed {
+		types[i] = data.Type
+	}
+	return types
+}
+
+func TestAnalyzeCompressedSegments(t *testing.T) {
+	profile := testProfile()
+	frame := testFrame(profile,
+		testSegment(1, []byte("before")),
+		testCompressedSegment(t, 99, testSegment(2, []byte("first")), testSegment(3, []byte("second"))),
+		testSegment(4, []byte("after")),
+	)
+
+	analyzed := AnalyzePayload(frame)
+	assertContents(t, analyzed, "before", "first", "second", "after")
+	if types := analyzedTypes(analyzed); !slices.Equal(types, []int{1, 2, 3, 4}) {
+		t.Fatalf("types = %v, want [1 2 3 4]", types)
+	}
+}
+
+func TestAnalyzeNestedCompressedSegment(t *testing.T) {
+	profile := testProfile()
+	inner := testCompressedSegment(t, 98, testSegment(3, []byte("inner")))
+	frame := testFrame(profile, testCompressedSegment(t, 99, testSegment(2, []byte("outer")), inner))
+
+	analyzed := AnalyzePayload(frame)
+	assertContents(t, analyzed, "outer", "inner")
+	for _, data := range analyzed {
+		if data.Err != nil {
+			t.Fatalf("segment %d: %v", data.Type, data.Err)
+		}
+	}
+}
+
+func TestAnalyzeCorruptCompressedSegment(t *testing.T) {
+	profile := testProfile()
+	corrupt := testSegment(99, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	corrupt[8] = 1
+
+	// 해제에 실패한 세그먼트만 오류로 남고 뒤의 세그먼트는 계속 처리됩니다
+	analyzed := AnalyzePayload(testFrame(profile, corrupt, testSegment(4, []byte("after"))))
+	if len(analyzed) != 2 {
+		t.Fatalf("got %d segments, want 2", len(analyzed))
+	}
+	if analyzed[0].Type != 99 || analyzed[0].Err == nil || !strings.Contains(analyzed[0].Err.Error(), "failed to decompress") {
+		t.Fatalf("corrupt segment = %+v, want a decompression error", analyzed[0])
+	}
+	if analyzed[1].Err != nil || string(analyzed[1].Content) != "after" {
+		t.Fatalf("segment after the corrupt one = %+v", analyzed[1])
+	}
+}
+
+func TestAnalyzeCompressionDepthLimit(t *testing.T) {
+	segment := testSegment(1, []byte("deepest"))
+	for depth := range maxCompressionDepth + 1 {
+		segment = testCompressedSegment(t, 100+depth, segment)
+	}
+
+	analyzed := AnalyzePayload(testFrame(testProfile(), segment))
+	if len(analyzed) != 1 {
+		t.Fatalf("got %d segments, want 1", len(analyzed))
+	}
+
+	// 가장 안쪽 압축 세그먼트는 해제하지 않고 오류로 남깁니다
+	if data := analyzed[0]; data.Type != 100 || data.Err == nil || !strings.Contains(data.Err.Error(), "nested too deep") {
+		t.Fatalf("segment = type %d, err %v, want a depth error on type 100", data.Type, data.Err)
+	}
+
+	// 한도 안의 깊이는 끝까지 해제합니다
+	segment = testSegment(1, []byte("deepest"))
+	for depth := range maxCompressionDepth {
+		segment = testCompressedSegment(t, 100+depth, segment)
+	}
+	assertContents(t, AnalyzePayload(testFrame(testProfile(), segment)), "deepest")
+}
+
+func TestDecompressBrotliSizeLimit(t *testing.T) {
+	compress := func(length int) []byte {
+		var compressed bytes.Buffer
+		writer := brotli.NewWriter(&compressed)
+		if _, err := writer.Write(make([]byte, length)); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return compressed.Bytes()
+	}
+
+	if data, err := decompressBrotli(compress(maxDecompressedSegmentLength)); err != nil || len(data) != maxDecompressedSegmentLength {
+		t.Fatalf("decompress at the limit = %d bytes, %v", len(data), err)
+	}
+
+	if _, err := decompressBrotli(compress(maxDecompressedSegmentLength + 1)); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("decompress over the limit = %v, want a size error", err)
+	}
+}
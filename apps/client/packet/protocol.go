@@ -0,0 +1,203 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 프로토콜 프로필 파일 형식 버전
+const profileFileVersion = 1
+
+// Profile 게임 버전별 프로토콜 정보 (타입 번호, 프레임 구분자, 서버 포트)
+type Profile struct {
+	Name           string           `json:"name"`
+	GameVersion    string           `json:"game_version,omitempty"`
+	Port           uint16           `json:"port"`
+	StartDelimiter HexBytes         `json:"start_delimiter"`
+	EndDelimiter   HexBytes         `json:"end_delimiter"`
+	Types          map[string][]int `json:"types"` // 내장 파서 이름 -> 데이터 타입 번호
+}
+
+// ProfileFile 프로토콜 프로필 파일의 최상위 구조
+type ProfileFile struct {
+	Version  int       `json:"version"`
+	Profiles []Profile `json:"profiles"`
+}
+
+// HexBytes JSON에서 "68 27 00 ..." 형태의 16진수 문자열로 표현되는 바이트열
+type HexBytes []byte
+
+func (h HexBytes) MarshalJSON() ([]byte, error) {
+	parts := make([]string, len(h))
+	for i, b := range h {
+		parts[i] = hex.EncodeToString([]byte{b})
+	}
+
+	return json.Marshal(strings.Join(parts, " "))
+}
+
+func (h *HexBytes) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	decoded, err := hex.DecodeString(strings.Join(strings.Fields(text), ""))
+	if err != nil {
+		return fmt.Errorf("invalid hex bytes %q: %w", text, err)
+	}
+
+	*h = decoded
+	return nil
+}
+
+var activeProfile atomic.Pointer[Profile]
+
+func init() {
+	// 기본 프로필은 항상 유효해야 합니다
+	if err := UseProfile(DefaultProfile()); err != nil {
+		panic(err)
+	}
+}
+
+// DefaultProfile 내장 기본 프로필을 반환합니다
+func DefaultProfile() Profile {
+	return Profile{
+		Name:           "default",
+		Port:           16000,
+		StartDelimiter: HexBytes{0x68, 0x27, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		EndDelimiter:   HexBytes{0xe3, 0x27, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		Types: map[string][]int{
+			"attack": {10308},
+			"hp":     {100178},
+			"action": {100041},
+		},
+	}
+}
+
+// CurrentProfile 현재 사용 중인 프로필을 반환합니다
+func CurrentProfile() Profile {
+	return *currentProfile()
+}
+
+func currentProfile() *Profile {
+	return activeProfile.Load()
+}
+
+// UseProfile 프로필을 적용합니다. 내장 파서들은 프로필의 타입 번호로 다시 등록됩니다.
+// 이미 만들어진 스트림은 생성 시점의 프로필을 계속 사용하므로 스니퍼 초기화 전에 호출해야 합니다.
+func UseProfile(profile Profile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	if err := registerBuiltinParsers(profile); err != nil {
+		return fmt.Errorf("profile %s: %w", profile.Name, err)
+	}
+
+	activeProfile.Store(&profile)
+	return nil
+}
+
+// Validate 프로필 값이 올바른지 확인합니다
+func (p Profile) Validate() error {
+	if p.Name == "" {
+		return errors.New("profile name is empty")
+	}
+
+	if p.Port == 0 {
+		return fmt.Errorf("profile %s: port is not set", p.Name)
+	}
+
+	if len(p.StartDelimiter) == 0 || len(p.EndDelimiter) == 0 {
+		return fmt.Errorf("profile %s: frame delimiters are not set", p.Name)
+	}
+
+	if bytes.Equal(p.StartDelimiter, p.EndDelimiter) {
+		return fmt.Errorf("profile %s: start and end delimiters are identical", p.Name)
+	}
+
+	seen := make(map[int]string)
+	for name, types := range p.Types {
+		if _, ok := builtinParsers[name]; !ok {
+			return fmt.Errorf("profile %s: unknown parser %q", p.Name, name)
+		}
+
+		for _, dataType := range types {
+			if dataType == 0 {
+				return fmt.Errorf("profile %s: parser %s uses reserved data type 0", p.Name, name)
+			}
+
+			if other, ok := seen[dataType]; ok {
+				return fmt.Errorf("profile %s: data type %d assigned to both %s and %s", p.Name, dataType, other, name)
+			}
+			seen[dataType] = name
+		}
+	}
+
+	return nil
+}
+
+// LoadProfiles 프로필 파일을 읽습니다
+func LoadProfiles(path string) ([]Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protocol profile file %s: %w", path, err)
+	}
+
+	var file ProfileFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse protocol profile file %s: %w", path, err)
+	}
+
+	if file.Version != profileFileVersion {
+		return nil, fmt.Errorf("unsupported protocol profile file version %d in %s (want %d)", file.Version, path, profileFileVersion)
+	}
+
+	if len(file.Profiles) == 0 {
+		return nil, fmt.Errorf("no profiles in %s", path)
+	}
+
+	for _, profile := range file.Profiles {
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return file.Profiles, nil
+}
+
+// SelectProfile 이름 또는 게임 버전이 key와 일치하는 프로필을 찾습니다. key가 비어 있으면 첫 번째 프로필을 반환합니다.
+func SelectProfile(profiles []Profile, key string) (Profile, error) {
+	if len(profiles) == 0 {
+		return Profile{}, errors.New("no profiles to select from")
+	}
+
+	if key == "" {
+		return profiles[0], nil
+	}
+
+	for _, profile := range profiles {
+		if profile.Name == key || (profile.GameVersion != "" && profile.GameVersion == key) {
+			return profile, nil
+		}
+	}
+
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		names[i] = profile.Name
+	}
+
+	return Profile{}, fmt.Errorf("profile %q not found (available: %s)", key, strings.Join(names, ", "))
+}
+
+// bpfFilter 현재 프로필의 서버 포트로 캡처 필터를 만듭니다
+func bpfFilter() string {
+	return fmt.Sprintf("tcp and port %d", currentProfile().Port)
+}
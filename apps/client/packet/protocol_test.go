@@ -0,0 +1,94 @@
+package packet
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeProfileFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "protocol.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadAndSelectProfile(t *testing.T) {
+	path := writeProfileFile(t, `{
+  "version": 1,
+  "profiles": [
+    {"name": "old", "game_version": "1.0", "port": 16000,
+     "start_delimiter": "68 27", "end_delimiter": "e3 27", "types": {"attack": [10308]}},
+    {"name": "new", "game_version": "1.1", "port": 16001,
+     "start_delimiter": "69 27", "end_delimiter": "e4 27", "types": {"attack": [10309], "hp": [100179]}}
+  ]
+}`)
+
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("LoadProfiles: %v", err)
+	}
+
+	tests := []struct {
+		key      string
+		wantName string
+		wantErr  string
+	}{
+		{"", "old", ""},
+		{"new", "new", ""},
+		{"1.1", "new", ""},
+		{"2.0", "", `profile "2.0" not found (available: old, new)`},
+	}
+
+	for _, tt := range tests {
+		profile, err := SelectProfile(profiles, tt.key)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("SelectProfile(%q) error = %v, want %q", tt.key, err, tt.wantErr)
+			}
+			continue
+		}
+
+		if err != nil || profile.Name != tt.wantName {
+			t.Fatalf("SelectProfile(%q) = %s, %v, want %s", tt.key, profile.Name, err, tt.wantName)
+		}
+	}
+
+	if selected, _ := SelectProfile(profiles, "new"); string(selected.StartDelimiter) != "\x69\x27" || selected.Port != 16001 {
+		t.Fatalf("profile new = %+v", selected)
+	}
+}
+
+func TestLoadProfilesRejectsInvalidFile(t *testing.T) {
+	valid := `"name": "p", "port": 16000, "start_delimiter": "68 27", "end_delimiter": "e3 27"`
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"bad json", `{`, "failed to parse"},
+		{"version", `{"version": 2, "profiles": [{` + valid + `}]}`, "unsupported protocol profile file version 2"},
+		{"no profiles", `{"version": 1, "profiles": []}`, "no profiles"},
+		{"bad hex", `{"version": 1, "profiles": [{"name": "p", "port": 1, "start_delimiter": "zz", "end_delimiter": "e3"}]}`, "invalid hex bytes"},
+		{"no port", `{"version": 1, "profiles": [{"name": "p", "start_delimiter": "68", "end_delimiter": "e3"}]}`, "port is not set"},
+		{"same delimiters", `{"version": 1, "profiles": [{"name": "p", "port": 1, "start_delimiter": "68", "end_delimiter": "68"}]}`, "identical"},
+		{"unknown parser", `{"version": 1, "profiles": [{` + valid + `, "types": {"heal": [1]}}]}`, `unknown parser "heal"`},
+		{"reserved type", `{"version": 1, "profiles": [{` + valid + `, "types": {"hp": [0]}}]}`, "reserved data type 0"},
+		{"duplicate type", `{"version": 1, "profiles": [{` + valid + `, "types": {"hp": [5], "attack": [5]}}]}`, "data type 5 assigned to both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadProfiles(writeProfileFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadProfiles error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
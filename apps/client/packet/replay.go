@@ -0,0 +1,303 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+)
+
+// ReplayMax PCAP 파일을 기다리지 않고 최대 속도로 읽는 재생 속도
+const ReplayMax = 0
+
+// ReplayStatus PCAP 재생 상태
+type ReplayStatus struct {
+	Position time.Duration // 첫 패킷 기준 마지막으로 보낸 패킷의 캡처 시각
+	Speed    float64       // 재생 배속. ReplayMax이면 최대 속도
+	Paused   bool
+	Seeking  bool // 탐색 위치까지 최대 속도로 읽는 중
+}
+
+// replayClock PCAP 패킷을 캡처 시각 간격에 맞춰 내보내는 시계.
+// 읽기 고루틴이 wait를 호출하고, 다른 고루틴이 속도, 일시 정지, 탐색을 바꿉니다.
+type replayClock struct {
+	mu      sync.Mutex
+	speed   float64
+	paused  bool
+	changed chan struct{} // 설정이 바뀌면 닫히고 새로 만들어집니다
+
+	origin   time.Time     // 재생의 첫 패킷 캡처 시각 (위치 0)
+	position time.Duration // 마지막으로 보낸 패킷의 위치
+	seekTo   time.Duration // 이 위치 전까지는 기다리지 않습니다
+	restart  bool          // 처음부터 다시 읽어야 함 (뒤로 탐색)
+
+	// 실제 시각 anchorWall에 캡처 시각 anchorCapture의 패킷을 보냈습니다
+	anchorWall    time.Time
+	anchorCapture time.Time
+}
+
+var replay = &replayClock{
+	speed:   ReplayMax,
+	changed: make(chan struct{}),
+}
+
+// ParseReplaySpeed "max", "0.5", "10x" 같은 재생 속도를 해석합니다
+func ParseReplaySpeed(value string) (float64, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "max" {
+		return ReplayMax, nil
+	}
+
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid replay speed %q: use max or a positive multiplier such as 0.5x, 1x or 10x", value)
+	}
+
+	return speed, nil
+}
+
+// SetReplaySpeed PCAP 재생 배속을 설정합니다. ReplayMax(0)이면 기다리지 않고 읽습니다.
+func SetReplaySpeed(speed float64) {
+	replay.update(func(c *replayClock) {
+		c.speed = max(speed, ReplayMax)
+	})
+}
+
+// PauseReplay PCAP 재생을 일시 정지합니다
+func PauseReplay() {
+	replay.update(func(c *replayClock) {
+		c.paused = true
+	})
+}
+
+// ResumeReplay 일시 정지한 PCAP 재생을 이어서 진행합니다
+func ResumeReplay() {
+	replay.update(func(c *replayClock) {
+		c.paused = false
+	})
+}
+
+// SeekReplay 첫 패킷 기준 offset 위치로 이동합니다. 그 위치까지의 패킷은 최대 속도로 재조립기에 넣고,
+// 현재 위치보다 앞이면 재조립기를 비우고 파일을 처음부터 다시 읽습니다.
+func SeekReplay(offset time.Duration) {
+	replay.update(func(c *replayClock) {
+		offset = max(offset, 0)
+		c.seekTo = offset
+		c.restart = offset < c.position
+	})
+}
+
+// Replay 현재 PCAP 재생 상태를 반환합니다
+func Replay() ReplayStatus {
+	replay.mu.Lock()
+	defer replay.mu.Unlock()
+
+	return ReplayStatus{
+		Position: replay.position,
+		Speed:    replay.speed,
+		Paused:   replay.paused,
+		Seeking:  replay.position < replay.seekTo,
+	}
+}
+
+// update 설정을 바꾸고 기다리는 읽기 고루틴을 깨웁니다
+func (c *replayClock) update(change func(*replayClock)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	change(c)
+	c.anchorWall = time.Time{}
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// rebase 다음 패킷부터 새로 간격을 잽니다 (파일이 바뀌어 캡처 시각이 이어지지 않을 때)
+func (c *replayClock) rebase() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.anchorWall = time.Time{}
+}
+
+// takeRestart 뒤로 탐색 요청이 있으면 지우고 true를 반환합니다
+func (c *replayClock) takeRestart() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	restart := c.restart
+	c.restart = false
+	if restart {
+		c.position = 0
+		c.anchorWall = time.Time{}
+	}
+	return restart
+}
+
+// wait 캡처 시각이 timestamp인 패킷을 보낼 때까지 기다립니다.
+// ctx가 취소되거나 뒤로 탐색 요청이 오면 false를 반환합니다.
+func (c *replayClock) wait(timestamp time.Time) bool {
+	for {
+		c.mu.Lock()
+		if c.restart {
+			c.mu.Unlock()
+			return false
+		}
+
+		if c.origin.IsZero() {
+			c.origin = timestamp
+		}
+		offset := max(timestamp.Sub(c.origin), c.position)
+		changed := c.changed
+
+		var delay time.Duration
+		switch {
+		case c.paused:
+			delay = -1
+		case c.speed == ReplayMax || offset < c.seekTo:
+			delay = 0
+		case c.anchorWall.IsZero():
+			c.anchorWall, c.anchorCapture = time.Now(), timestamp
+		default:
+			due := c.anchorWall.Add(time.Duration(float64(timestamp.Sub(c.anchorCapture)) / c.speed))
+			delay = max(time.Until(due), 0)
+		}
+
+		if delay == 0 {
+			c.position = offset
+			if offset < c.seekTo {
+				// 탐색이 끝나면 그 위치부터 다시 간격을 잽니다
+				c.anchorWall = time.Time{}
+			}
+		}
+		c.mu.Unlock()
+
+		if delay == 0 {
+			return true
+		}
+
+		// 일시 정지 중에는 설정이 바뀔 때까지 기다립니다
+		timer := time.NewTimer(delay)
+		if delay < 0 {
+			timer.Stop()
+		}
+
+		select {
+		case <-timer.C:
+			c.mu.Lock()
+			c.position = offset
+			c.mu.Unlock()
+			return true
+		case <-changed:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+// readReplay PCAP 파일들을 순서대로 재생 시계에 맞춰 out으로 보냅니다.
+// 뒤로 탐색하면 재조립기 초기화를 요청하고 파일들을 다시 열어 처음부터 읽습니다.
+func readReplay(out chan<- capturedPacket, files []*captureSource) {
+	for {
+		stopped := false
+		for _, source := range files {
+			if stopped = !source.replay(out); stopped {
+				break
+			}
+		}
+
+		if !stopped || !replay.takeRestart() {
+			return
+		}
+
+		log.Printf("Replay restarting from the beginning to seek backwards")
+		select {
+		case out <- capturedPacket{restart: true}:
+		case <-ctx.Done():
+			return
+		}
+
+		for _, source := range files {
+			if err := source.reopen(); err != nil {
+				log.Printf("Replay failed to reopen %s: %v", source.name, err)
+				return
+			}
+		}
+	}
+}
+
+// replay 파일 하나를 재생합니다. ctx가 취소되거나 뒤로 탐색 요청이 오면 false를 반환합니다.
+func (s *captureSource) replay(out chan<- capturedPacket) bool {
+	replay.rebase()
+
+	// 핸들은 이 고루틴의 reopen에서만 바뀌므로 재생하는 동안 그대로입니다
+	handle := s.current()
+	for {
+		data, captureInfo, err := handle.ReadPacketData()
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		if err != nil {
+			log.Printf("Failed to read %s: %v", s.name, err)
+			return true
+		}
+
+		if !replay.wait(captureInfo.Timestamp) {
+			return false
+		}
+
+		packet := gopacket.NewPacket(data, handle.LinkType(), gopacket.Default)
+		packet.Metadata().CaptureInfo = captureInfo
+		s.packets.Add(1)
+
+		select {
+		case out <- capturedPacket{packet: packet, source: s}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// openReplayFile PCAP 파일을 열고 캡처 필터를 적용합니다. 테스트에서는 준비한 패킷을 돌려주는 핸들로 바꿉니다.
+var openReplayFile = func(name string) (packetHandle, error) {
+	handle, err := pcap.OpenOffline(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := handle.SetBPFFilter(captureFilter()); err != nil {
+		handle.Close()
+		return nil, err
+	}
+
+	return handle, nil
+}
+
+// reopen PCAP 파일을 다시 열어 처음부터 읽을 수 있게 합니다. 이미 닫힌 소스는 다시 열지 않습니다.
+func (s *captureSource) reopen() error {
+	handle, err := openReplayFile(s.name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		handle.Close()
+		return errSourceClosed
+	}
+
+	s.handle.Close()
+	s.handle = handle
+	s.packets.Store(0)
+	return nil
+}
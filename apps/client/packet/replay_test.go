@@ -0,0 +1,229 @@
+package packet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// testAttackFrame userID의 공격 세그먼트 하나를 담은 프레임을 만듭니다
+func testAttackFrame(userID uint32) []byte {
+	content := bytes.Clone(attackFixture)
+	binary.LittleEndian.PutUint32(content, userID)
+
+	profile := currentProfile()
+	return testFrame(profile, testSegment(profile.Types["attack"][0], content))
+}
+
+// useTestReplay 테스트가 끝나면 되돌릴 새 재생 시계와 재조립기를 설치하고, 취소할 수 있는 ctx를 반환합니다
+func useTestReplay(t *testing.T, speed float64) context.CancelFunc {
+	previousCtx, previousAssembler, previousReplay, previousOpen := ctx, assembler, replay, openReplayFile
+	t.Cleanup(func() {
+		ctx, assembler, replay, openReplayFile = previousCtx, previousAssembler, previousReplay, previousOpen
+	})
+
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	assembler = newAssembler()
+	replay = &replayClock{speed: speed, changed: make(chan struct{})}
+	return cancel
+}
+
+// waitAsync replay.wait(timestamp)를 다른 고루틴에서 호출하고 결과를 보낼 채널을 반환합니다
+func waitAsync(timestamp time.Time) <-chan bool {
+	done := make(chan bool, 1)
+	go func() { done <- replay.wait(timestamp) }()
+	return done
+}
+
+func assertWaiting(t *testing.T, done <-chan bool) {
+	t.Helper()
+
+	select {
+	case ok := <-done:
+		t.Fatalf("wait returned %v, want it to block", ok)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func assertReleased(t *testing.T, done <-chan bool, want bool) {
+	t.Helper()
+
+	select {
+	case ok := <-done:
+		if ok != want {
+			t.Fatalf("wait = %v, want %v", ok, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return")
+	}
+}
+
+func TestParseReplaySpeed(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{"max", ReplayMax, false},
+		{" MAX ", ReplayMax, false},
+		{"1", 1, false},
+		{"0.5x", 0.5, false},
+		{"10X", 10, false},
+		{"0", 0, true},
+		{"0x", 0, true},
+		{"-1", 0, true},
+		{"-2x", 0, true},
+		{"fast", 0, true},
+		{"x", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		speed, err := ParseReplaySpeed(tt.value)
+		if (err != nil) != tt.wantErr || speed != tt.want {
+			t.Errorf("ParseReplaySpeed(%q) = %v, %v, want %v (error %v)", tt.value, speed, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestReplayPauseResume(t *testing.T) {
+	useTestReplay(t, ReplayMax)
+	start := time.Unix(1700000000, 0)
+
+	assertReleased(t, waitAsync(start), true)
+
+	PauseReplay()
+	if !Replay().Paused {
+		t.Fatal("replay not paused")
+	}
+
+	// 최대 속도여도 일시 정지 중에는 다음 패킷을 보내지 않습니다
+	done := waitAsync(start.Add(time.Second))
+	assertWaiting(t, done)
+
+	ResumeReplay()
+	assertReleased(t, done, true)
+
+	if status := Replay(); status.Paused || status.Position != time.Second {
+		t.Fatalf("status = %+v, want resumed at 1s", status)
+	}
+}
+
+func TestReplayForwardSeek(t *testing.T) {
+	cancel := useTestReplay(t, 1)
+	start := time.Unix(1700000000, 0)
+
+	assertReleased(t, waitAsync(start), true)
+
+	// 탐색 위치까지의 패킷은 실제 시간 간격을 기다리지 않습니다
+	SeekReplay(time.Hour)
+	assertReleased(t, waitAsync(start.Add(30*time.Minute)), true)
+	if status := Replay(); !status.Seeking || status.Position != 30*time.Minute {
+		t.Fatalf("status = %+v, want seeking at 30m", status)
+	}
+
+	assertReleased(t, waitAsync(start.Add(time.Hour)), true)
+	if status := Replay(); status.Seeking || status.Position != time.Hour {
+		t.Fatalf("status = %+v, want seek finished at 1h", status)
+	}
+
+	// 탐색 위치부터는 다시 배속에 맞춰 기다립니다
+	done := waitAsync(start.Add(time.Hour + time.Minute))
+	assertWaiting(t, done)
+
+	cancel()
+	assertReleased(t, done, false)
+}
+
+// seekingHandle after개의 패킷을 읽은 뒤 다음 패킷을 읽기 전에 offset으로 탐색합니다
+type seekingHandle struct {
+	*testHandle
+	after  int
+	offset time.Duration
+}
+
+func (h *seekingHandle) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	if h.after == 0 {
+		SeekReplay(h.offset)
+	}
+	h.after--
+
+	return h.testHandle.ReadPacketData()
+}
+
+func TestReplayBackwardSeek(t *testing.T) {
+	useTestReplay(t, ReplayMax)
+
+	start := time.Unix(1700000000, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	frames := [][]byte{testAttackFrame(1), testAttackFrame(2), testAttackFrame(3)}
+	seq := []uint32{1001, 1001 + uint32(len(frames[0])), 1001 + uint32(len(frames[0])+len(frames[1]))}
+	packets := []gopacket.Packet{
+		testTCPPacket(t, at(0), false, layers.TCP{Seq: 100, SYN: true}, nil),
+		testTCPPacket(t, at(10), true, layers.TCP{Seq: 1000, Ack: 101, SYN: true, ACK: true}, nil),
+		testTCPPacket(t, at(20), true, layers.TCP{Seq: seq[0], Ack: 101, ACK: true, PSH: true}, frames[0]),
+		testTCPPacket(t, at(30), true, layers.TCP{Seq: seq[1], Ack: 101, ACK: true, PSH: true}, frames[1]),
+		testTCPPacket(t, at(40), true, layers.TCP{Seq: seq[2], Ack: 101, ACK: true, PSH: true}, frames[2]),
+	}
+
+	// 첫 공격 프레임까지 보낸 뒤 처음으로 되돌아갑니다
+	first := &testHandle{packets: packets}
+	source := &captureSource{name: "test.pcap", handle: &seekingHandle{testHandle: first, after: 3}}
+
+	var reopened []string
+	openReplayFile = func(name string) (packetHandle, error) {
+		reopened = append(reopened, name)
+		return &testHandle{packets: packets}, nil
+	}
+
+	events := Subscribe(16)
+	captured := make(chan capturedPacket)
+	go func() {
+		defer close(captured)
+		readReplay(captured, []*captureSource{source})
+	}()
+
+	assemblePackets(captured, false, nil)
+	assembler.FlushAll()
+	events.Close()
+
+	var got []any
+	for event := range events.C {
+		switch payload := event.Payload.(type) {
+		case AttackData:
+			got = append(got, payload.UserID)
+		case StreamReset:
+			got = append(got, "reset")
+		default:
+			t.Fatalf("unexpected event %+v", event)
+		}
+	}
+
+	// 되돌아가기 전의 스트림을 초기화하고, 다시 연 파일을 처음부터 읽습니다
+	want := []any{uint32(1), "reset", uint32(1), uint32(2), uint32(3)}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(reopened, []string{"test.pcap"}) {
+		t.Fatalf("reopened = %v, want test.pcap once", reopened)
+	}
+	if !first.closed {
+		t.Fatal("previous handle was not closed")
+	}
+	if count := source.packets.Load(); count != uint64(len(packets)) {
+		t.Fatalf("packets = %d after the restart, want %d", count, len(packets))
+	}
+	if status := Replay(); status.Position != 40*time.Millisecond || status.Seeking {
+		t.Fatalf("status = %+v, want finished at 40ms", status)
+	}
+}
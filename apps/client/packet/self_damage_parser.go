@@ -0,0 +1,26 @@
+package packet
+
+import "fmt"
+
+// SelfDamageData 캐릭터가 받은 피해 (타입 10701, 10719).
+// 필드 위치는 캡처로 확인되지 않았으므로 기본 프로필에는 등록하지 않습니다.
+// 확인한 뒤 프로토콜 파일의 types에 "self_damage": [10701, 10719]를 추가하면 사용할 수 있습니다.
+type SelfDamageData struct {
+	AttackerID uint32 `layout:"u32,at=8"`
+	VictimID   uint32 `layout:"u32,at=0"`
+	Damage     uint32 `layout:"u32,at=16"`
+	// 플래그 비트 배치는 공격 패킷과 같습니다
+	Flags DamageFlags `layout:"flags,at=24,size=7,set=damage"`
+}
+
+const selfDamageDataMinLength = 31
+
+var selfDamageLayout = mustNewLayout[SelfDamageData]("self_damage")
+
+func parseSelfDamage(data []byte) (SelfDamageData, error) {
+	if len(data) < selfDamageDataMinLength {
+		return SelfDamageData{}, fmt.Errorf("self damage packet too short: %d bytes, need at least %d", len(data), selfDamageDataMinLength)
+	}
+
+	return selfDamageLayout.Decode(data)
+}
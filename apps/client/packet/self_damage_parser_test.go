@@ -0,0 +1,61 @@
+package packet
+
+import (
+	"strings"
+	"testing"
+)
+
+// selfDamageFixture SelfDamageData가 가정한 배치대로 만든 세그먼트. 실제 캡처가 아니므로 파서가 구현한 배치만 고정합니다.
+// 사용하지 않는 구간(4-7, 12-15, 20-23)에도 값을 채워 다른 필드로 새어 들어가지 않는지 확인합니다.
+var selfDamageFixture = []byte{
+	0x11, 0x22, 0x33, 0x44, // 0: VictimID
+	0xde, 0xad, 0xbe, 0xef,
+	0x55, 0x66, 0x77, 0x08, // 8: AttackerID
+	0xde, 0xad, 0xbe, 0xef,
+	0x39, 0x30, 0x00, 0x00, // 16: Damage (12345)
+	0xde, 0xad, 0xbe, 0xef,
+	0x01, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, // 24: 플래그 (crit, fire)
+}
+
+func TestParseSelfDamageLayout(t *testing.T) {
+	got, err := parseSelfDamage(selfDamageFixture)
+	if err != nil {
+		t.Fatalf("parseSelfDamage: %v", err)
+	}
+
+	want := SelfDamageData{
+		VictimID:   0x44332211,
+		AttackerID: 0x08776655,
+		Damage:     12345,
+		Flags:      DamageFlags(0).With(FlagCrit).With(FlagFire),
+	}
+	if got != want {
+		t.Fatalf("parseSelfDamage = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSelfDamageIgnoresTrailingBytes(t *testing.T) {
+	data := append(append([]byte(nil), selfDamageFixture...), 0xff, 0xff, 0xff, 0xff)
+
+	got, err := parseSelfDamage(data)
+	if err != nil {
+		t.Fatalf("parseSelfDamage: %v", err)
+	}
+
+	if got.Damage != 12345 || got.Flags.String() != "crit|fire" {
+		t.Fatalf("parseSelfDamage = %+v, want damage 12345 and flags crit|fire", got)
+	}
+}
+
+func TestParseSelfDamageTooShort(t *testing.T) {
+	_, err := parseSelfDamage(selfDamageFixture[:selfDamageDataMinLength-1])
+	if err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Fatalf("parseSelfDamage error = %v, want too short", err)
+	}
+}
+
+func TestSelfDamageNotInDefaultProfile(t *testing.T) {
+	if types, ok := DefaultProfile().Types["self_damage"]; ok {
+		t.Fatalf("default profile registers unverified self_damage types %v", types)
+	}
+}
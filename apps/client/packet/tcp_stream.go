@@ -1,15 +1,44 @@
 package packet
 
 import (
+	"encoding/binary"
+	"net/netip"
+	"time"
+
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/reassembly"
 )
 
+// Direction 게임 서버를 기준으로 한 데이터 흐름 방향
+type Direction uint8
+
+const (
+	DirectionUnknown Direction = iota
+	DirectionClientToServer
+	DirectionServerToClient
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionClientToServer:
+		return "client->server"
+	case DirectionServerToClient:
+		return "server->client"
+	default:
+		return "unknown"
+	}
+}
+
 type tcpStreamFactory struct{}
 
 type tcpStream struct {
 	net, transport gopacket.Flow
+	server         netip.AddrPort
+	srcIsServer    bool
+
+	// 방향마다 프레임 경계가 독립적이므로 디코더를 따로 둡니다
+	toServer, toClient *frameDecoder
 }
 
 type Context struct {
@@ -22,9 +51,22 @@ func (c *Context) GetCaptureInfo() gopacket.CaptureInfo {
 }
 
 func (t *tcpStreamFactory) New(net, transport gopacket.Flow, tcp *layers.TCP, ac reassembly.AssemblerContext) reassembly.Stream {
+	// 서버 포트가 출발지인 경우에만 출발지를 서버로 보고, 그 외에는 연결을 시작한 쪽(출발지)을 클라이언트로 간주합니다
+	profile := currentProfile()
+	srcIsServer := endpointPort(transport.Src()) == profile.Port
+
+	serverNet, serverPort := net.Dst(), transport.Dst()
+	if srcIsServer {
+		serverNet, serverPort = net.Src(), transport.Src()
+	}
+
 	return &tcpStream{
-		net:       net,
-		transport: transport,
+		net:         net,
+		transport:   transport,
+		server:      endpointAddrPort(serverNet, serverPort),
+		srcIsServer: srcIsServer,
+		toServer:    newFrameDecoder(maxFrameBufferLength, profile),
+		toClient:    newFrameDecoder(maxFrameBufferLength, profile),
 	}
 }
 
@@ -33,25 +75,70 @@ func (t *tcpStream) Accept(tcp *layers.TCP, ci gopacket.CaptureInfo, dir reassem
 }
 
 func (t *tcpStream) ReassembledSG(sg reassembly.ScatterGather, ac reassembly.AssemblerContext) {
-	if ac == nil {
-		return
-	}
+	flowDir, _, _, skip := sg.Info()
 
-	if context, ok := ac.(*Context); !ok || context.TCP == nil || !context.TCP.PSH {
-		return
+	direction := t.direction(flowDir)
+	decoder := t.toServer
+	if direction == DirectionServerToClient {
+		decoder = t.toClient
 	}
 
-	_, _, skip, _ := sg.Info()
+	// 누락된 구간이 있으면 이어 붙일 수 없으므로 미완성 프레임을 버립니다
+	if skip > 0 {
+		decoder.Reset()
+	}
 
-	if skip {
+	length, _ := sg.Lengths()
+	if length == 0 {
 		return
 	}
 
-	length, _ := sg.Lengths()
+	datas := decoder.Decode(sg.Fetch(length))
+	for i := range datas {
+		datas[i].Direction = direction
+		datas[i].Server = t.server
+	}
 
-	analyzePayload(sg.Fetch(length))
+	analyzePayload(datas, captureTimestamp(sg, ac), t.net, t.transport)
 }
 
 func (t *tcpStream) ReassemblyComplete(ac reassembly.AssemblerContext) bool {
 	return false
 }
+
+// direction 재조립기의 흐름 방향(스트림 생성 시의 출발지 기준)을 게임 서버 기준 방향으로 변환합니다
+func (t *tcpStream) direction(dir reassembly.TCPFlowDirection) Direction {
+	fromSrc := dir == reassembly.TCPDirClientToServer
+	if fromSrc == t.srcIsServer {
+		return DirectionServerToClient
+	}
+
+	return DirectionClientToServer
+}
+
+// captureTimestamp 재조립된 데이터를 완성한 패킷의 캡처 시각을 반환합니다
+func captureTimestamp(sg reassembly.ScatterGather, ac reassembly.AssemblerContext) time.Time {
+	if ac != nil {
+		return ac.GetCaptureInfo().Timestamp
+	}
+
+	return sg.CaptureInfo(0).Timestamp
+}
+
+func endpointPort(ep gopacket.Endpoint) uint16 {
+	raw := ep.Raw()
+	if len(raw) != 2 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint16(raw)
+}
+
+func endpointAddrPort(netEndpoint, portEndpoint gopacket.Endpoint) netip.AddrPort {
+	addr, ok := netip.AddrFromSlice(netEndpoint.Raw())
+	if !ok {
+		return netip.AddrPort{}
+	}
+
+	return netip.AddrPortFrom(addr.Unmap(), endpointPort(portEndpoint))
+}
@@ -0,0 +1,126 @@
+package packet
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/reassembly"
+)
+
+// testScatterGather 재조립된 데이터 한 덩어리
+type testScatterGather struct {
+	data      []byte
+	direction reassembly.TCPFlowDirection
+	skip      int
+	timestamp time.Time
+}
+
+func (sg *testScatterGather) Lengths() (int, int)     { return len(sg.data), 0 }
+func (sg *testScatterGather) Fetch(length int) []byte { return sg.data[:length] }
+func (sg *testScatterGather) KeepFrom(offset int)     {}
+func (sg *testScatterGather) CaptureInfo(offset int) gopacket.CaptureInfo {
+	return gopacket.CaptureInfo{Timestamp: sg.timestamp}
+}
+func (sg *testScatterGather) Info() (reassembly.TCPFlowDirection, bool, bool, int) {
+	return sg.direction, false, false, sg.skip
+}
+func (sg *testScatterGather) Stats() reassembly.TCPAssemblyStats {
+	return reassembly.TCPAssemblyStats{}
+}
+
+// testFlows from에서 to로 가는 패킷의 네트워크/전송 계층 흐름을 만듭니다
+func testFlows(from, to netip.AddrPort) (gopacket.Flow, gopacket.Flow) {
+	src, dst := from.Addr().As4(), to.Addr().As4()
+	netFlow := gopacket.NewFlow(layers.EndpointIPv4, net.IP(src[:]), net.IP(dst[:]))
+	transport := gopacket.NewFlow(layers.EndpointTCPPort,
+		binary.BigEndian.AppendUint16(nil, from.Port()), binary.BigEndian.AppendUint16(nil, to.Port()))
+	return netFlow, transport
+}
+
+// testAttackSegment userID의 공격 세그먼트를 만듭니다
+func testAttackSegment(userID uint32) []byte {
+	content := make([]byte, attackDataLength)
+	binary.LittleEndian.PutUint32(content, userID)
+	return testSegment(currentProfile().Types["attack"][0], content)
+}
+
+func TestTCPStreamDirection(t *testing.T) {
+	server := netip.AddrPortFrom(netip.MustParseAddr("10.0.0.1"), currentProfile().Port)
+	client := netip.MustParseAddrPort("10.0.0.2:50000")
+
+	tests := []struct {
+		name     string
+		from, to netip.AddrPort
+		// 스트림을 만든 첫 패킷의 방향(TCPDirClientToServer)이 게임 서버 기준으로 어느 방향인지
+		want Direction
+	}{
+		{"client sends first", client, server, DirectionClientToServer},
+		{"server sends first", server, client, DirectionServerToClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			netFlow, transport := testFlows(tt.from, tt.to)
+			stream := (&tcpStreamFactory{}).New(netFlow, transport, &layers.TCP{}, nil).(*tcpStream)
+
+			if stream.server != server {
+				t.Fatalf("server = %s, want %s", stream.server, server)
+			}
+			if got := stream.direction(reassembly.TCPDirClientToServer); got != tt.want {
+				t.Fatalf("first packet direction = %s, want %s", got, tt.want)
+			}
+			if got := stream.direction(reassembly.TCPDirServerToClient); got == tt.want || got == DirectionUnknown {
+				t.Fatalf("reverse direction = %s", got)
+			}
+		})
+	}
+}
+
+func TestTCPStreamSeparateDecoders(t *testing.T) {
+	server := netip.AddrPortFrom(netip.MustParseAddr("10.0.0.1"), currentProfile().Port)
+	client := netip.MustParseAddrPort("10.0.0.2:50000")
+	netFlow, transport := testFlows(client, server)
+	stream := (&tcpStreamFactory{}).New(netFlow, transport, &layers.TCP{}, nil).(*tcpStream)
+
+	events := Subscribe(8)
+	defer events.Close()
+
+	profile := currentProfile()
+	up := testFrame(profile, testAttackSegment(1))
+	down := testFrame(profile, testAttackSegment(2))
+	start := time.Unix(1700000000, 0)
+
+	// 한 방향의 미완성 프레임이 다른 방향의 프레임과 섞이지 않아야 합니다
+	half := len(up) / 2
+	stream.ReassembledSG(&testScatterGather{data: up[:half], direction: reassembly.TCPDirClientToServer, timestamp: start}, nil)
+	stream.ReassembledSG(&testScatterGather{data: down, direction: reassembly.TCPDirServerToClient, timestamp: start.Add(time.Second)}, nil)
+	if stream.toServer.pending() != half || stream.toClient.pending() != 0 {
+		t.Fatalf("pending = %d to server, %d to client, want %d and 0", stream.toServer.pending(), stream.toClient.pending(), half)
+	}
+	stream.ReassembledSG(&testScatterGather{data: up[half:], direction: reassembly.TCPDirClientToServer, timestamp: start.Add(2 * time.Second)}, nil)
+
+	want := []struct {
+		userID    uint32
+		direction Direction
+		timestamp time.Time
+	}{
+		{2, DirectionServerToClient, start.Add(time.Second)},
+		{1, DirectionClientToServer, start.Add(2 * time.Second)},
+	}
+	for _, w := range want {
+		select {
+		case event := <-events.C:
+			attack, ok := event.Payload.(AttackData)
+			if !ok || attack.UserID != w.userID || event.Direction != w.direction || event.Server != server || !event.Timestamp.Equal(w.timestamp) {
+				t.Fatalf("event = %+v (%s, %s), want user %d %s from %s", event.Payload, event.Direction, event.Server, w.userID, w.direction, server)
+			}
+		default:
+			t.Fatalf("missing event for user %d", w.userID)
+		}
+	}
+}
@@ -21,13 +21,10 @@ type QUICClient struct {
 	tlsConf *tls.Config
 }
 
-func NewQUICClient(addr string) *QUICClient {
+func NewQUICClient(addr string, tlsConf *tls.Config) *QUICClient {
 	return &QUICClient{
-		addr: addr,
-		tlsConf: &tls.Config{
-			InsecureSkipVerify: true, // 개발용 자가서명 인증서 허용
-			NextProtos:         []string{"mogi-suction-quic"},
-		},
+		addr:    addr,
+		tlsConf: tlsConf,
 	}
 }
 
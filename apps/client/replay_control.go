@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"mogi-suction/client/packet"
+)
+
+const replayControlHelp = "replay commands: pause, resume, speed <max|0.5x|1x|10x>, seek <offset such as 90s or 5m>, status"
+
+// readReplayControls r에서 한 줄씩 PCAP 재생 명령을 읽어 처리합니다. r이 닫히면 끝납니다.
+func readReplayControls(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		command, args := strings.ToLower(fields[0]), fields[1:]
+		switch {
+		case command == "pause" || command == "p":
+			packet.PauseReplay()
+		case command == "resume" || command == "r":
+			packet.ResumeReplay()
+		case (command == "speed" || command == "x") && len(args) == 1:
+			speed, err := packet.ParseReplaySpeed(args[0])
+			if err != nil {
+				log.Printf("Replay: %v", err)
+				continue
+			}
+			packet.SetReplaySpeed(speed)
+		case (command == "seek" || command == "s") && len(args) == 1:
+			offset, err := time.ParseDuration(args[0])
+			if err != nil {
+				log.Printf("Replay: invalid seek offset %q: %v", args[0], err)
+				continue
+			}
+			packet.SeekReplay(offset)
+		case command == "status":
+		default:
+			log.Print(replayControlHelp)
+			continue
+		}
+
+		logReplayStatus()
+	}
+}
+
+func logReplayStatus() {
+	status := packet.Replay()
+
+	speed := "max"
+	if status.Speed != packet.ReplayMax {
+		speed = fmt.Sprintf("%gx", status.Speed)
+	}
+
+	state := "playing"
+	switch {
+	case status.Paused:
+		state = "paused"
+	case status.Seeking:
+		state = "seeking"
+	}
+
+	log.Printf("Replay %s at %s, speed %s", state, status.Position.Round(time.Millisecond), speed)
+}